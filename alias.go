@@ -0,0 +1,108 @@
+package validator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var aliases = make(map[string][]ValidateTag)
+
+// RegisterAlias registers a named validator chain. The chain is expanded in
+// place wherever the alias is used in a tag. Chain arguments like $1 and $2
+// are substituted with the alias arguments: given
+//
+//	RegisterAlias("bounded_str", "nonempty, maxlen($1)")
+//
+// a tag `validate:"bounded_str(64)"` expands to `nonempty, maxlen(64)`.
+func RegisterAlias(handle, chain string) error {
+	if _, ok := validators[handle]; ok {
+		return duplicateValidatorDefErr(handle)
+	}
+	if _, ok := aliases[handle]; ok {
+		return duplicateValidatorDefErr(handle)
+	}
+	if _, ok := normalizers[handle]; ok {
+		return duplicateValidatorDefErr(handle)
+	}
+	aliases[handle] = parseValidateTags(chain)
+	if err := checkAliasCycle(handle, nil); err != nil {
+		delete(aliases, handle)
+		return err
+	}
+	return nil
+}
+
+func checkAliasCycle(handle string, stack []string) error {
+	for _, h := range stack {
+		if h == handle {
+			return aliasCycleErr(append(stack, handle))
+		}
+	}
+	for _, tag := range aliases[handle] {
+		if _, ok := aliases[tag.Op]; ok {
+			if err := checkAliasCycle(tag.Op, append(stack, handle)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func expandAliases(tags []ValidateTag) ([]ValidateTag, error) {
+	return doExpandAliases(tags, nil)
+}
+
+func doExpandAliases(tags []ValidateTag, stack []string) ([]ValidateTag, error) {
+	res := make([]ValidateTag, 0, len(tags))
+	for _, tag := range tags {
+		chain, ok := aliases[tag.Op]
+		if !ok {
+			res = append(res, tag)
+			continue
+		}
+		for _, h := range stack {
+			if h == tag.Op {
+				return nil, aliasCycleErr(append(stack, tag.Op))
+			}
+		}
+		alias := tag.Alias
+		if alias == "" {
+			alias = tag.Op
+		}
+		subst := make([]ValidateTag, 0, len(chain))
+		for _, ctag := range chain {
+			args := make([]interface{}, 0, len(ctag.Args))
+			for _, arg := range ctag.Args {
+				s, ok := arg.(string)
+				if !ok || !strings.HasPrefix(s, "$") {
+					args = append(args, arg)
+					continue
+				}
+				ix, err := strconv.Atoi(s[1:])
+				if err != nil || ix < 1 {
+					return nil, fmt.Errorf("Alias %q has malformed parameter %s", tag.Op, s)
+				}
+				if ix > len(tag.Args) {
+					return nil, fmt.Errorf("Alias %q expects parameter %s, %d arguments given", tag.Op, s, len(tag.Args))
+				}
+				args = append(args, tag.Args[ix-1])
+			}
+			subst = append(subst, ValidateTag{
+				Op:    ctag.Op,
+				Args:  args,
+				Alias: alias,
+			})
+		}
+		expanded, err := doExpandAliases(subst, append(stack, tag.Op))
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, expanded...)
+	}
+	return res, nil
+}
+
+func aliasCycleErr(stack []string) error {
+	return fmt.Errorf("Alias cycle detected: %s", strings.Join(stack, " -> "))
+}
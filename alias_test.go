@@ -0,0 +1,101 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterAlias(t *testing.T) {
+	assert.NoError(t, RegisterAlias("alias_bounded_str", "nonempty, maxlen($1)"))
+	assert.NoError(t, RegisterAlias("alias_title", "alias_bounded_str(8)"))
+	assert.NoError(t, RegisterAlias("alias_between", "gte($1), lte($2)"))
+
+	assert.Error(t, RegisterAlias("alias_title", "nonempty"))
+	assert.Error(t, RegisterAlias("maxlen", "nonempty"))
+	assert.Error(t, Register("alias_title", StdNonEmpty))
+
+	type TestStruct struct {
+		Title  string `validate:"alias_title"`
+		Name   string `validate:"alias_bounded_str(3)"`
+		Rating int    `validate:"alias_between(1, 5)"`
+	}
+
+	tests := []struct {
+		name       string
+		input      TestStruct
+		wantErr    string
+		wantHandle string
+		wantAlias  string
+	}{
+		{
+			name:  "valid",
+			input: TestStruct{Title: "foo", Name: "bar", Rating: 3},
+		},
+		{
+			name:       "nested alias",
+			input:      TestStruct{Title: "foobarbaz", Name: "bar", Rating: 3},
+			wantErr:    `Validation failed for field "Title": length must be up to 8`,
+			wantHandle: "maxlen",
+			wantAlias:  "alias_title",
+		},
+		{
+			name:       "first chain element",
+			input:      TestStruct{Title: "foo", Rating: 3},
+			wantErr:    `Validation failed for field "Name": should not be empty`,
+			wantHandle: "nonempty",
+			wantAlias:  "alias_bounded_str",
+		},
+		{
+			name:       "second parameter",
+			input:      TestStruct{Title: "foo", Name: "bar", Rating: 6},
+			wantErr:    `Validation failed for field "Rating": should be less or equal to 5`,
+			wantHandle: "lte",
+			wantAlias:  "alias_between",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.input)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Equal(t, tt.wantErr, err.Error())
+			fe, ok := err.(*FieldError)
+			if assert.True(t, ok) {
+				assert.Equal(t, tt.wantHandle, fe.Handle)
+				assert.Equal(t, tt.wantAlias, fe.Alias)
+			}
+		})
+	}
+}
+
+func TestRegisterAlias_Params(t *testing.T) {
+	assert.NoError(t, RegisterAlias("alias_params", "range($1, $2)"))
+
+	type TestStruct struct {
+		Attr int `validate:"alias_params(1)"`
+	}
+
+	err := Validate(TestStruct{Attr: 1})
+	assert.Error(t, err)
+	assert.Equal(t, `Alias "alias_params" expects parameter $2, 1 arguments given`, err.Error())
+}
+
+func TestRegisterAlias_Cycle(t *testing.T) {
+	assert.NoError(t, RegisterAlias("alias_cycle_a", "nonempty, alias_cycle_b"))
+	assert.NoError(t, RegisterAlias("alias_cycle_b", "alias_cycle_c(1)"))
+
+	err := RegisterAlias("alias_cycle_c", "gt($1), alias_cycle_a")
+	assert.Error(t, err)
+	assert.Equal(t, "Alias cycle detected: alias_cycle_c -> alias_cycle_a -> alias_cycle_b -> alias_cycle_c", err.Error())
+
+	assert.NoError(t, RegisterAlias("alias_cycle_c", "gt($1)"))
+	type TestStruct struct {
+		Attr int `validate:"alias_cycle_a"`
+	}
+	assert.NoError(t, Validate(TestStruct{Attr: 2}))
+}
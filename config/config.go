@@ -0,0 +1,327 @@
+// Package config populates configuration structs from defaults, environment
+// variables and command line flags and validates them.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/osdrv/validator"
+	"github.com/osdrv/validator/internal/convert"
+)
+
+const (
+	EnvTagName     = "env"
+	FlagTagName    = "flag"
+	DefaultTagName = "default"
+	UsageTagName   = "usage"
+)
+
+// Error is a configuration error reported in terms of the environment
+// variable or the flag the failed field is set by.
+type Error struct {
+	Name    string
+	Message string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s %s", e.Name, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+type Errors []*Error
+
+func (e Errors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+type Option func(*options)
+
+type options struct {
+	lookupEnv func(string) (string, bool)
+	flags     *flag.FlagSet
+	validate  []validator.Option
+}
+
+// WithEnv replaces os.LookupEnv as the source of the environment variables.
+func WithEnv(lookup func(string) (string, bool)) Option {
+	return func(o *options) {
+		o.lookupEnv = lookup
+	}
+}
+
+// WithFlags applies the flags set on the parsed fs to the fields tagged with
+// flag. The flags left unset do not override the other sources.
+func WithFlags(fs *flag.FlagSet) Option {
+	return func(o *options) {
+		o.flags = fs
+	}
+}
+
+// WithValidatorOptions passes opts to the validation.
+func WithValidatorOptions(opts ...validator.Option) Option {
+	return func(o *options) {
+		o.validate = opts
+	}
+}
+
+// Load populates the struct pointed to by v and validates it with every
+// error collected. A field keeps its value unless it is zero and has a
+// default tag, the environment variable named by its env tag is set or the
+// flag named by its flag tag is set, the latter taking precedence. Nested
+// structs and struct pointers are populated the same way, a nil pointer is
+// allocated when any of its fields is set. Slices are set from
+// comma-separated values, an empty value sets an empty slice.
+//
+// The default tag takes precedence over a default normalizer in the validate
+// tag, which only applies to a field that is still zero once it is loaded.
+//
+// The malformed and invalid values are reported in Errors. The value of a
+// field with the sensitive directive in its validate tag is left out.
+func Load(v interface{}, opts ...Option) error {
+	o := &options{lookupEnv: os.LookupEnv}
+	for _, opt := range opts {
+		opt(o)
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("Load accepts a pointer to a struct, %T given", v)
+	}
+	l := &loader{
+		options: o,
+		names:   make(map[string]string),
+		set:     make(map[string]bool),
+	}
+	if o.flags != nil {
+		o.flags.Visit(func(f *flag.Flag) {
+			l.set[f.Name] = true
+		})
+	}
+	if errs := l.load(rv.Elem(), ""); len(errs) > 0 {
+		return errs
+	}
+
+	// The loader records the embedded struct fields by their full path
+	err := validator.Validate(v, append(o.validate, validator.WithAllErrors(), validator.WithEmbeddedNames())...)
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return err
+	}
+	errs := make(Errors, 0, len(verrs))
+	for _, fe := range verrs {
+		errs = append(errs, &Error{Name: l.name(fe.Path), Message: fe.Message, Err: fe})
+	}
+	return errs
+}
+
+type loader struct {
+	*options
+	// names maps the field paths to the variables they are set by
+	names map[string]string
+	set   map[string]bool
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func (l *loader) load(v reflect.Value, prefix string) Errors {
+	var errs Errors
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fv := v.Field(i)
+		// The exported fields of an embedded struct are promoted even when
+		// its type is unexported
+		if field.PkgPath != "" && !(field.Anonymous && fv.Kind() == reflect.Struct) {
+			continue
+		}
+		path := prefix + field.Name
+		if nested(fv.Type()) {
+			errs = append(errs, l.load(fv, path+".")...)
+			continue
+		}
+		if fv.Kind() == reflect.Ptr && nested(fv.Type().Elem()) {
+			p := fv
+			if fv.IsNil() {
+				p = reflect.New(fv.Type().Elem())
+			}
+			errs = append(errs, l.load(p.Elem(), path+".")...)
+			if fv.IsNil() && !p.Elem().IsZero() {
+				fv.Set(p)
+			}
+			continue
+		}
+		sensitive := isSensitive(field)
+
+		env := field.Tag.Get(EnvTagName)
+		flagName := field.Tag.Get(FlagTagName)
+		switch {
+		case env != "":
+			l.names[path] = env
+		case flagName != "":
+			l.names[path] = "-" + flagName
+		}
+
+		if def, ok := field.Tag.Lookup(DefaultTagName); ok && fv.IsZero() {
+			if err := setValue(fv, def); err != nil {
+				errs = append(errs, malformed(path, "has a malformed default", err, sensitive))
+			}
+		}
+		if s, ok := l.lookupEnv(env); ok && env != "" {
+			if err := setValue(fv, s); err != nil {
+				errs = append(errs, malformed(env, "is malformed", err, sensitive))
+			}
+		}
+		if flagName != "" && l.set[flagName] {
+			l.names[path] = "-" + flagName
+			if err := setValue(fv, l.flags.Lookup(flagName).Value.String()); err != nil {
+				errs = append(errs, malformed("-"+flagName, "is malformed", err, sensitive))
+			}
+		}
+	}
+	return errs
+}
+
+// name returns the variable the field at path is set by, falling back to
+// the field path.
+func (l *loader) name(path string) string {
+	for p := path; p != ""; {
+		if name, ok := l.names[p]; ok {
+			return name + strings.TrimPrefix(path, p)
+		}
+		i := strings.LastIndexAny(p, ".[")
+		if i < 0 {
+			break
+		}
+		p = p[:i]
+	}
+	return path
+}
+
+// errSensitive stands for a conversion error of a sensitive field, which
+// quotes the value.
+var errSensitive = errors.New("malformed sensitive value")
+
+func malformed(name, message string, err error, sensitive bool) *Error {
+	if sensitive {
+		return &Error{Name: name, Message: message, Err: errSensitive}
+	}
+	return &Error{Name: name, Message: message + ": " + err.Error(), Err: err}
+}
+
+// isSensitive tells if the validate tag of field has the sensitive
+// directive.
+func isSensitive(field reflect.StructField) bool {
+	for _, op := range strings.Split(field.Tag.Get(validator.ValidateTagName), ",") {
+		if strings.TrimSpace(op) == validator.SensitiveDirective {
+			return true
+		}
+	}
+	return false
+}
+
+// nested tells if the fields of t are loaded one by one.
+func nested(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != timeType
+}
+
+func setValue(v reflect.Value, s string) error {
+	if v.Kind() == reflect.Slice {
+		if s == "" {
+			v.Set(reflect.MakeSlice(v.Type(), 0, 0))
+			return nil
+		}
+		return convert.SetValues(v, strings.Split(s, ","))
+	}
+	return convert.SetString(v, s)
+}
+
+// DefineFlags defines a flag on fs for every field of the struct pointed to
+// by v tagged with flag, using the usage tag as the flag usage and the
+// current value as the default. Pass fs to Load with WithFlags once it is
+// parsed.
+func DefineFlags(fs *flag.FlagSet, v interface{}) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("DefineFlags accepts a pointer to a struct, %T given", v))
+	}
+	defineFlags(fs, rv.Elem())
+}
+
+func defineFlags(fs *flag.FlagSet, v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fv := v.Field(i)
+		// The exported fields of an embedded struct are promoted even when
+		// its type is unexported
+		if field.PkgPath != "" && !(field.Anonymous && fv.Kind() == reflect.Struct) {
+			continue
+		}
+		if nested(fv.Type()) {
+			defineFlags(fs, fv)
+			continue
+		}
+		if fv.Kind() == reflect.Ptr && nested(fv.Type().Elem()) {
+			if fv.IsNil() {
+				defineFlags(fs, reflect.New(fv.Type().Elem()).Elem())
+			} else {
+				defineFlags(fs, fv.Elem())
+			}
+			continue
+		}
+		name := field.Tag.Get(FlagTagName)
+		if name == "" {
+			continue
+		}
+		def := field.Tag.Get(DefaultTagName)
+		if !fv.IsZero() {
+			def = formatValue(fv)
+		}
+		val := &flagValue{value: def, bool: fv.Kind() == reflect.Bool}
+		fs.Var(val, name, field.Tag.Get(UsageTagName))
+		fs.Lookup(name).DefValue = def
+	}
+}
+
+func formatValue(v reflect.Value) string {
+	if v.Kind() == reflect.Slice {
+		vals := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			vals = append(vals, fmt.Sprint(v.Index(i).Interface()))
+		}
+		return strings.Join(vals, ",")
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// flagValue holds the flag value as given, Load converts it.
+type flagValue struct {
+	value string
+	bool  bool
+}
+
+func (f *flagValue) String() string {
+	return f.value
+}
+
+func (f *flagValue) Set(s string) error {
+	f.value = s
+	return nil
+}
+
+func (f *flagValue) IsBoolFlag() bool {
+	return f.bool
+}
@@ -0,0 +1,197 @@
+package config
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testDB struct {
+	URL      string `env:"DB_URL" validate:"nonempty"`
+	PoolSize int    `env:"DB_POOL_SIZE" flag:"db-pool-size" default:"10" validate:"range(1, 100)"`
+}
+
+type testConfig struct {
+	DB      testDB
+	Addr    string        `env:"ADDR" flag:"addr" default:":8080" usage:"listen address" validate:"nonempty"`
+	Timeout time.Duration `env:"TIMEOUT" default:"5s" validate:"gt(0)"`
+	Debug   bool          `flag:"debug" usage:"verbose logging"`
+	Origins []string      `env:"ORIGINS" validate:"optional, maxitems(2)"`
+	Name    string        `validate:"nonempty"`
+}
+
+func testEnv(env map[string]string) Option {
+	return WithEnv(func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	})
+}
+
+func TestLoad(t *testing.T) {
+	cfg := testConfig{Name: "api"}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	DefineFlags(fs, &cfg)
+	assert.NoError(t, fs.Parse([]string{"-addr", ":9090", "-debug"}))
+
+	err := Load(&cfg, WithFlags(fs), testEnv(map[string]string{
+		"DB_URL":  "postgres://localhost/db",
+		"ADDR":    ":7070",
+		"ORIGINS": "a.com,b.com",
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, testConfig{
+		DB:      testDB{URL: "postgres://localhost/db", PoolSize: 10},
+		Addr:    ":9090",
+		Timeout: 5 * time.Second,
+		Debug:   true,
+		Origins: []string{"a.com", "b.com"},
+		Name:    "api",
+	}, cfg)
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "env names",
+			env:     map[string]string{"DB_POOL_SIZE": "500", "ORIGINS": "a,b,c"},
+			wantErr: "DB_URL should not be empty; DB_POOL_SIZE should be in the range [1, 100]; ORIGINS should contain at most 2 items",
+		},
+		{
+			name:    "flag names",
+			env:     map[string]string{"DB_URL": "db", "DB_POOL_SIZE": "500"},
+			args:    []string{"-db-pool-size", "0"},
+			wantErr: "-db-pool-size should be in the range [1, 100]",
+		},
+		{
+			name:    "malformed values",
+			env:     map[string]string{"DB_URL": "db", "TIMEOUT": "soon"},
+			args:    []string{"-db-pool-size", "many"},
+			wantErr: `-db-pool-size is malformed: invalid value "many", want int; TIMEOUT is malformed: invalid value "soon", want a duration`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testConfig{Name: "api"}
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			DefineFlags(fs, &cfg)
+			assert.NoError(t, fs.Parse(tt.args))
+
+			err := Load(&cfg, WithFlags(fs), testEnv(tt.env))
+			assert.EqualError(t, err, tt.wantErr)
+			_, ok := err.(Errors)
+			assert.True(t, ok)
+		})
+	}
+}
+
+func TestLoad_StructPointer(t *testing.T) {
+	type testCache struct {
+		URL string `env:"CACHE_URL"`
+	}
+	type pointerConfig struct {
+		DB    *testDB
+		Cache *testCache
+	}
+
+	cfg := pointerConfig{}
+	err := Load(&cfg, testEnv(map[string]string{"DB_URL": "db", "DB_POOL_SIZE": "500"}))
+	assert.EqualError(t, err, "DB_POOL_SIZE should be in the range [1, 100]")
+	assert.Nil(t, cfg.Cache)
+
+	cfg = pointerConfig{}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	DefineFlags(fs, &cfg)
+	assert.NoError(t, fs.Parse([]string{"-db-pool-size", "20"}))
+	assert.NoError(t, Load(&cfg, WithFlags(fs), testEnv(map[string]string{"DB_URL": "db", "CACHE_URL": "redis://cache"})))
+	assert.Equal(t, pointerConfig{DB: &testDB{URL: "db", PoolSize: 20}, Cache: &testCache{URL: "redis://cache"}}, cfg)
+}
+
+func TestLoad_Sensitive(t *testing.T) {
+	type secretConfig struct {
+		Pin  int    `env:"PIN" validate:"sensitive, range(1000, 9999)"`
+		Port int    `env:"PORT"`
+		Key  string `env:"KEY" validate:"sensitive, nonempty"`
+	}
+
+	cfg := secretConfig{}
+	err := Load(&cfg, testEnv(map[string]string{"PIN": "hunter2", "PORT": "http"}))
+	assert.EqualError(t, err, `PIN is malformed; PORT is malformed: invalid value "http", want int`)
+	assert.NotContains(t, fmt.Sprintf("%+v", err.(Errors)[0].Err), "hunter2")
+}
+
+func TestLoad_EmptySlice(t *testing.T) {
+	type tagsConfig struct {
+		Tags []string `env:"TAGS" validate:"minitems(1)"`
+	}
+
+	cfg := tagsConfig{Tags: []string{"default"}}
+	err := Load(&cfg, testEnv(map[string]string{"TAGS": ""}))
+	assert.EqualError(t, err, "TAGS should contain at least 1 items")
+	assert.Empty(t, cfg.Tags)
+}
+
+func TestLoad_UntaggedField(t *testing.T) {
+	cfg := testConfig{}
+	err := Load(&cfg, testEnv(map[string]string{"DB_URL": "db"}))
+	assert.EqualError(t, err, "Name should not be empty")
+}
+
+func TestLoad_EmbeddedStruct(t *testing.T) {
+	type embeddedConfig struct {
+		testDB
+		Name string `validate:"nonempty"`
+	}
+
+	cfg := embeddedConfig{}
+	err := Load(&cfg, testEnv(map[string]string{"DB_URL": "db", "DB_POOL_SIZE": "0"}))
+	assert.EqualError(t, err, "DB_POOL_SIZE should be in the range [1, 100]; Name should not be empty")
+
+	cfg = embeddedConfig{}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	DefineFlags(fs, &cfg)
+	assert.NoError(t, fs.Parse([]string{"-db-pool-size", "500"}))
+	err = Load(&cfg, WithFlags(fs), testEnv(map[string]string{"DB_URL": "db"}))
+	assert.EqualError(t, err, "-db-pool-size should be in the range [1, 100]; Name should not be empty")
+}
+
+func TestLoad_DefaultPrecedence(t *testing.T) {
+	type defaultsConfig struct {
+		Both     int `default:"10" validate:"default(20)"`
+		Validate int `env:"LIMIT" validate:"default(20)"`
+	}
+
+	cfg := defaultsConfig{}
+	assert.NoError(t, Load(&cfg, testEnv(nil)))
+	assert.Equal(t, defaultsConfig{Both: 10, Validate: 20}, cfg)
+
+	cfg = defaultsConfig{}
+	assert.NoError(t, Load(&cfg, testEnv(map[string]string{"LIMIT": "30"})))
+	assert.Equal(t, defaultsConfig{Both: 10, Validate: 30}, cfg)
+}
+
+func TestDefineFlags(t *testing.T) {
+	cfg := testConfig{Debug: true}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	DefineFlags(fs, &cfg)
+
+	var out bytes.Buffer
+	fs.SetOutput(&out)
+	fs.PrintDefaults()
+	assert.Equal(t, `  -addr value
+    	listen address (default :8080)
+  -db-pool-size value
+    	 (default 10)
+  -debug
+    	verbose logging (default true)
+`, out.String())
+}
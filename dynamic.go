@@ -0,0 +1,29 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// TypeCheckHandle is the FieldError handle reported for a dynamic type not
+// allowed by WithAllowedTypes.
+const TypeCheckHandle = "type"
+
+func (s *validation) checkDynamicType(v reflect.Value, path fieldPath) error {
+	allowed, ok := s.allowed[v.Type()]
+	if !ok {
+		return nil
+	}
+	dt := v.Elem().Type()
+	for _, t := range allowed {
+		if t == dt {
+			return nil
+		}
+	}
+	var value interface{}
+	if v.CanInterface() {
+		value = v.Elem().Interface()
+	}
+	err := &mismatchError{reason: fmt.Sprintf("dynamic type %v is not allowed", dt)}
+	return s.fieldError(path.name(), path, "", ValidateTag{Op: TypeCheckHandle, Args: []interface{}{}}, value, err, false)
+}
@@ -0,0 +1,126 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testEventPayload interface {
+	Kind() string
+}
+
+type testCreated struct {
+	Title string `validate:"nonempty"`
+}
+
+func (*testCreated) Kind() string { return "created" }
+
+type testDeleted struct {
+	Reason string `validate:"nonempty"`
+}
+
+func (testDeleted) Kind() string { return "deleted" }
+
+type testRenamed struct {
+	Title string `validate:"nonempty"`
+}
+
+func (testRenamed) Kind() string { return "renamed" }
+
+func TestValidateInterfaceFields(t *testing.T) {
+	type Event struct {
+		Payload testEventPayload `validate:"required"`
+		Extra   interface{}
+		History []testEventPayload
+	}
+
+	allowed := WithAllowedTypes((*testEventPayload)(nil), &testCreated{}, testDeleted{})
+
+	tests := []struct {
+		name       string
+		input      Event
+		opts       []Option
+		wantErr    string
+		wantPath   string
+		wantHandle string
+	}{
+		{
+			name:  "valid pointer payload",
+			input: Event{Payload: &testCreated{Title: "foo"}},
+		},
+		{
+			name:       "nil payload",
+			input:      Event{},
+			wantErr:    `Validation failed for field "Payload": is required`,
+			wantPath:   "Payload",
+			wantHandle: "required",
+		},
+		{
+			name:       "invalid pointer payload",
+			input:      Event{Payload: &testCreated{}},
+			wantErr:    `Validation failed for field "Title": should not be empty`,
+			wantPath:   "Payload.(*validator.testCreated).Title",
+			wantHandle: "nonempty",
+		},
+		{
+			name:       "invalid value payload",
+			input:      Event{Payload: testDeleted{}},
+			wantErr:    `Validation failed for field "Reason": should not be empty`,
+			wantPath:   "Payload.(validator.testDeleted).Reason",
+			wantHandle: "nonempty",
+		},
+		{
+			name:       "any field",
+			input:      Event{Payload: testDeleted{Reason: "foo"}, Extra: &testCreated{}},
+			wantErr:    `Validation failed for field "Title": should not be empty`,
+			wantPath:   "Extra.(*validator.testCreated).Title",
+			wantHandle: "nonempty",
+		},
+		{
+			name:       "interface collection",
+			input:      Event{Payload: testDeleted{Reason: "foo"}, History: []testEventPayload{testDeleted{Reason: "bar"}, &testCreated{}}},
+			wantErr:    `Validation failed for field "Title": should not be empty`,
+			wantPath:   "History[1].(*validator.testCreated).Title",
+			wantHandle: "nonempty",
+		},
+		{
+			name:  "allowed type",
+			input: Event{Payload: &testCreated{Title: "foo"}, Extra: testRenamed{Title: "bar"}},
+			opts:  []Option{allowed},
+		},
+		{
+			name:       "not allowed type",
+			input:      Event{Payload: testRenamed{Title: "foo"}},
+			opts:       []Option{allowed},
+			wantErr:    `Validation failed for field "Payload": dynamic type validator.testRenamed is not allowed`,
+			wantPath:   "Payload",
+			wantHandle: TypeCheckHandle,
+		},
+		{
+			name:       "not allowed type in collection",
+			input:      Event{Payload: testDeleted{Reason: "foo"}, History: []testEventPayload{testRenamed{Title: "foo"}}},
+			opts:       []Option{allowed},
+			wantErr:    `Validation failed for field "History": dynamic type validator.testRenamed is not allowed`,
+			wantPath:   "History[0]",
+			wantHandle: TypeCheckHandle,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.input, tt.opts...)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Equal(t, tt.wantErr, err.Error())
+			fe, ok := err.(*FieldError)
+			if assert.True(t, ok) {
+				assert.Equal(t, tt.wantPath, fe.Path)
+				assert.Equal(t, tt.wantHandle, fe.Handle)
+			}
+		})
+	}
+}
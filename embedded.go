@@ -0,0 +1,81 @@
+package validator
+
+import "reflect"
+
+// embedding describes an embedded struct whose fields are promoted to the
+// outer struct.
+type embedding struct {
+	outer reflect.Type
+	index []int
+	// path is the qualified path of the embedded struct.
+	path fieldPath
+}
+
+func isPromoted(field reflect.StructField) bool {
+	if !field.Anonymous {
+		return false
+	}
+	if name := jsonName(field); name != field.Name {
+		// encoding/json does not promote the fields of a named embedded struct
+		return false
+	}
+	t := field.Type
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
+
+// fieldPaths returns the path a field is reported with and its qualified
+// path. A field of an embedded struct is reported with the promoted path
+// unless it is shadowed by another field of the outer struct.
+func (e *embedding) fieldPaths(path fieldPath, field reflect.StructField, i int) (fieldPath, fieldPath) {
+	if e == nil {
+		fpath := path.structField(field)
+		return fpath, fpath
+	}
+	qpath := e.path.structField(field)
+	index := append(append(make([]int, 0, len(e.index)+1), e.index...), i)
+	if sf, ok := e.outer.FieldByName(field.Name); ok && equalIndex(sf.Index, index) {
+		return path.structField(field), qpath
+	}
+	return qpath, qpath
+}
+
+func (e *embedding) nest(outer reflect.Type, i int, path fieldPath) *embedding {
+	if e == nil {
+		return &embedding{
+			outer: outer,
+			index: []int{i},
+			path:  path,
+		}
+	}
+	return &embedding{
+		outer: e.outer,
+		index: append(append(make([]int, 0, len(e.index)+1), e.index...), i),
+		path:  path,
+	}
+}
+
+func (s *validation) descendEmbedded(v reflect.Value, path fieldPath, embed *embedding) error {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() || s.enter(v) {
+			return nil
+		}
+		defer s.leave(v)
+		v = v.Elem()
+	}
+	return s.validateStruct(v, path, embed)
+}
+
+func equalIndex(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
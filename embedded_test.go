@@ -0,0 +1,152 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testMeta struct {
+	CreatedAt int64  `json:"createdAt" validate:"gt(0)"`
+	Name      string `validate:"nonempty"`
+}
+
+type testAudit struct {
+	Author string `json:"author" validate:"nonempty"`
+}
+
+type testSecret struct {
+	secret string `validate:"nonempty"`
+	Public string `validate:"nonempty"`
+}
+
+func TestValidateEmbedded(t *testing.T) {
+	type Document struct {
+		testMeta
+		*testAudit
+		Name string `validate:"maxlen(3)"`
+	}
+
+	tests := []struct {
+		name        string
+		input       Document
+		opts        []Option
+		wantErr     string
+		wantPath    string
+		wantPointer string
+	}{
+		{
+			name:  "valid",
+			input: Document{testMeta: testMeta{CreatedAt: 1, Name: "foo"}, Name: "bar"},
+		},
+		{
+			name:        "promoted field",
+			input:       Document{testMeta: testMeta{Name: "foo"}},
+			wantErr:     `Validation failed for field "CreatedAt": should be greater than 0`,
+			wantPath:    "CreatedAt",
+			wantPointer: "/createdAt",
+		},
+		{
+			name:        "qualified field",
+			input:       Document{testMeta: testMeta{Name: "foo"}},
+			opts:        []Option{WithEmbeddedNames()},
+			wantErr:     `Validation failed for field "CreatedAt": should be greater than 0`,
+			wantPath:    "testMeta.CreatedAt",
+			wantPointer: "/testMeta/createdAt",
+		},
+		{
+			name:        "shadowed field",
+			input:       Document{testMeta: testMeta{CreatedAt: 1}},
+			wantErr:     `Validation failed for field "Name": should not be empty`,
+			wantPath:    "testMeta.Name",
+			wantPointer: "/testMeta/Name",
+		},
+		{
+			name:        "shadowing field",
+			input:       Document{testMeta: testMeta{CreatedAt: 1, Name: "foo"}, Name: "foobar"},
+			wantErr:     `Validation failed for field "Name": length must be up to 3`,
+			wantPath:    "Name",
+			wantPointer: "/Name",
+		},
+		{
+			name:        "embedded pointer",
+			input:       Document{testMeta: testMeta{CreatedAt: 1, Name: "foo"}, testAudit: &testAudit{}},
+			wantErr:     `Validation failed for field "Author": should not be empty`,
+			wantPath:    "Author",
+			wantPointer: "/author",
+		},
+		{
+			name:     "partial validation of a promoted field",
+			input:    Document{testMeta: testMeta{Name: "foo"}, Name: "foobar"},
+			opts:     []Option{WithFields("createdAt")},
+			wantErr:  `Validation failed for field "CreatedAt": should be greater than 0`,
+			wantPath: "CreatedAt",
+		},
+		{
+			name:     "partial validation of an embedded struct",
+			input:    Document{testMeta: testMeta{Name: "foo"}, Name: "foobar"},
+			opts:     []Option{WithFields("testMeta")},
+			wantErr:  `Validation failed for field "CreatedAt": should be greater than 0`,
+			wantPath: "CreatedAt",
+		},
+		{
+			name:  "excluded embedded struct",
+			input: Document{Name: "bar"},
+			opts:  []Option{WithoutFields("testMeta")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.input, tt.opts...)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Equal(t, tt.wantErr, err.Error())
+			fe, ok := err.(*FieldError)
+			if assert.True(t, ok) {
+				assert.Equal(t, tt.wantPath, fe.Path)
+				if tt.wantPointer != "" {
+					assert.Equal(t, tt.wantPointer, fe.Pointer)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateEmbedded_JSONName(t *testing.T) {
+	type Document struct {
+		testAudit `json:"audit"`
+	}
+
+	err := Validate(Document{})
+	assert.Error(t, err)
+	fe, ok := err.(*FieldError)
+	if assert.True(t, ok) {
+		assert.Equal(t, "testAudit.Author", fe.Path)
+		assert.Equal(t, "/audit/author", fe.Pointer)
+	}
+}
+
+func TestValidateUnexported(t *testing.T) {
+	type Document struct {
+		testSecret
+		inner *testSecret
+		local string `validate:"nonempty"`
+	}
+
+	var err error
+
+	assert.NotPanics(t, func() {
+		err = Validate(Document{testSecret: testSecret{Public: "foo"}, inner: &testSecret{}})
+	})
+	assert.NoError(t, err)
+
+	assert.NotPanics(t, func() {
+		err = Validate(&Document{})
+	})
+	assert.Error(t, err)
+	assert.Equal(t, `Validation failed for field "Public": should not be empty`, err.Error())
+}
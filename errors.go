@@ -0,0 +1,107 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+)
+
+type FieldError struct {
+	Field   string
+	Path    string
+	Pointer string
+	Handle  string
+	Alias   string
+	Args    []interface{}
+	Value   interface{}
+	Reason  string
+	Message string
+	Err     error
+
+	text string
+}
+
+func (e *FieldError) Error() string {
+	if e.text != "" {
+		return e.text
+	}
+	if e.Field == "" {
+		return fmt.Sprintf("Validation failed: %s", e.Message)
+	}
+	return fmt.Sprintf("Validation failed for field %q: %s", e.Field, e.Message)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationErrors holds every failed field when the validation runs with
+// WithAllErrors.
+type ValidationErrors []*FieldError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, fe := range e {
+		msgs = append(msgs, fe.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// UsageError is returned by a validator applied to a value of a type it does
+// not support or given malformed arguments. Unlike a failed constraint it is
+// reported as is, the message translations and the message tag do not apply.
+type UsageError struct {
+	Reason string
+}
+
+func (e *UsageError) Error() string {
+	return e.Reason
+}
+
+func usageErrorf(format string, args ...interface{}) *UsageError {
+	return &UsageError{Reason: fmt.Sprintf(format, args...)}
+}
+
+// ElementError is a failed constraint on an element of a collection, e.g.
+// a duplicate found by unique. The validators return it to make the element
+// available to the message templates in MessageData.
+type ElementError struct {
+	Reason string
+	// Index is the index of the element in a slice or an array, -1 for a map
+	Index int
+	// Key is the key of the element in a map
+	Key interface{}
+	// Other is the index of the element the offending one conflicts with,
+	// -1 if none
+	Other int
+}
+
+func (e *ElementError) Error() string {
+	return e.Reason
+}
+
+func elementErrorf(index int, format string, args ...interface{}) *ElementError {
+	return &ElementError{Reason: fmt.Sprintf(format, args...), Index: index, Other: -1}
+}
+
+type mismatchError struct {
+	reason string
+	err    error
+}
+
+func (e *mismatchError) Error() string {
+	return e.reason
+}
+
+func (e *mismatchError) Unwrap() error {
+	return e.err
+}
+
+type DepthError struct {
+	Path     string
+	Pointer  string
+	MaxDepth int
+}
+
+func (e *DepthError) Error() string {
+	return fmt.Sprintf("Maximum validation depth of %d exceeded at %q", e.MaxDepth, e.Path)
+}
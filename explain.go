@@ -0,0 +1,154 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// TraceKind is the kind of a TraceStep.
+type TraceKind uint8
+
+const (
+	// TraceStruct is a struct the validation descended into.
+	TraceStruct TraceKind = iota
+	// TraceField is a field of the struct being checked.
+	TraceField
+	// TraceCheck is a validator evaluated for the field.
+	TraceCheck
+	// TraceSkip is a field or a nested value left unchecked, see Note.
+	TraceSkip
+)
+
+// TraceStep is an entry of the Explain trace. The steps nested in a
+// struct or a field have a greater Depth.
+type TraceStep struct {
+	Kind  TraceKind
+	Depth int
+	Path  string
+	// Type is the type of a TraceStruct
+	Type   reflect.Type
+	Handle string
+	// Args are the validator arguments, converted to the type of a numeric
+	// value
+	Args []interface{}
+	// Err is the failure of a TraceCheck
+	Err error
+	// Break is set for a TraceCheck that passed and broke the chain
+	Break bool
+	// Note is the reason of a TraceSkip, "embedded" for the TraceStruct of
+	// an embedded struct
+	Note string
+}
+
+func (st TraceStep) String() string {
+	switch st.Kind {
+	case TraceStruct:
+		if st.Note != "" {
+			return fmt.Sprintf("%s %v", st.Note, st.Type)
+		}
+		if st.Path == "" {
+			return st.Type.String()
+		}
+		return fmt.Sprintf("descend %s (%v)", st.Path, st.Type)
+	case TraceField:
+		return st.Path
+	case TraceCheck:
+		call := st.Handle
+		if len(st.Args) > 0 {
+			args := make([]string, 0, len(st.Args))
+			for _, arg := range st.Args {
+				args = append(args, fmt.Sprint(arg))
+			}
+			call += "(" + strings.Join(args, ", ") + ")"
+		}
+		var fe *FieldError
+		switch {
+		case errors.As(st.Err, &fe):
+			return fmt.Sprintf("%s: fail: %s", call, fe.Message)
+		case st.Err != nil:
+			return fmt.Sprintf("%s: error: %s", call, st.Err)
+		case st.Break:
+			return call + ": pass, chain broken"
+		}
+		return call + ": pass"
+	case TraceSkip:
+		if st.Path == "" {
+			return "skip: " + st.Note
+		}
+		return fmt.Sprintf("skip %s: %s", st.Path, st.Note)
+	}
+	return ""
+}
+
+// Trace is the ordered list of steps taken by the validation.
+type Trace []TraceStep
+
+// String renders the trace as a tree indented by the step depths.
+func (t Trace) String() string {
+	var b strings.Builder
+	for _, st := range t {
+		b.WriteString(strings.Repeat("  ", st.Depth))
+		b.WriteString(st.String())
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// Explain validates datum the way Validate does and returns the trace of the
+// validation along with its result: every field visited, every validator
+// evaluated with its arguments and outcome, the chain breaks and the nested
+// values descended into or skipped. The normalizers run as in Validate but
+// are not traced.
+func Explain(datum interface{}, opts ...Option) (Trace, error) {
+	s := newValidation(context.Background(), opts...)
+	s.trace = &Trace{}
+	s.start(datum)
+	err := s.end(s.result(s.validate(datum)))
+	return *s.trace, err
+}
+
+// traceStep appends st to the trace of the check pass, if any.
+func (s *validation) traceStep(st TraceStep) {
+	if s.trace == nil || s.pass != passCheck {
+		return
+	}
+	st.Depth = s.traceDepth
+	*s.trace = append(*s.trace, st)
+}
+
+func (s *validation) traceSkip(path fieldPath, note string) {
+	s.traceStep(TraceStep{Kind: TraceSkip, Path: path.path, Note: note})
+}
+
+// traceArgs converts args to the type of a numeric value the way the
+// comparisons do.
+func traceArgs(value interface{}, args []interface{}) []interface{} {
+	v := reflect.ValueOf(value)
+	if !v.IsValid() || !isNumeric(v.Type()) {
+		return args
+	}
+	conv := make([]interface{}, 0, len(args))
+	for _, arg := range args {
+		cv, err := convStringVal(fmt.Sprint(arg), v.Kind())
+		if err != nil {
+			conv = append(conv, arg)
+			continue
+		}
+		conv = append(conv, cv.Convert(v.Type()).Interface())
+	}
+	return conv
+}
+
+// diveable reports whether the validation would descend into v.
+func diveable(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Struct, reflect.Ptr, reflect.Interface:
+		return true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return isStructCollection(v.Type())
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testExplainAddr struct {
+	City string `validate:"nonempty"`
+}
+
+type testExplainMeta struct {
+	ID int `validate:"gt(0)"`
+}
+
+type testExplain struct {
+	testExplainMeta
+	Name   string `validate:"nonempty, maxlen(5)"`
+	Nick   string `validate:"optional, minlen(3)"`
+	Age    uint8  `validate:"range(1, 150)"`
+	Home   *testExplainAddr
+	Work   *testExplainAddr  `validate:"optional"`
+	Prev   []testExplainAddr `validate:"maxitems(3)"`
+	Raw    *testExplainAddr  `validate:"nodive"`
+	Secret string            `validate:"-"`
+	Kind   string            `validate:"enum(a, b)"`
+}
+
+func TestExplain(t *testing.T) {
+	v := testExplain{
+		testExplainMeta: testExplainMeta{ID: 1},
+		Name:            "foo",
+		Age:             200,
+		Prev:            []testExplainAddr{{City: "a"}, {}},
+		Raw:             &testExplainAddr{},
+		Kind:            "a",
+	}
+	trace, err := Explain(v, WithAllErrors())
+	assert.EqualError(t, err, `Validation failed for field "Age": should be in the range [1, 150]; Validation failed for field "City": should not be empty`)
+	assert.Equal(t, `validator.testExplain
+  testExplainMeta
+    embedded validator.testExplainMeta
+      ID
+        gt(0): pass
+  Name
+    nonempty: pass
+    maxlen(5): pass
+  Nick
+    optional: pass, chain broken
+  Age
+    range(1, 150): fail: should be in the range [1, 150]
+  Home
+    skip Home: nil pointer
+  Work
+    optional: pass, chain broken
+    skip Work: chain broken, not descended
+  Prev
+    maxitems(3): pass
+    descend Prev[0] (validator.testExplainAddr)
+      Prev[0].City
+        nonempty: pass
+    descend Prev[1] (validator.testExplainAddr)
+      Prev[1].City
+        nonempty: fail: should not be empty
+  Raw
+    skip Raw: nodive directive
+  skip Secret: skip directive
+  Kind
+    enum(a, b): pass
+`, trace.String())
+
+	var check TraceStep
+	for _, st := range trace {
+		if st.Kind == TraceCheck && st.Path == "Age" {
+			check = st
+		}
+	}
+	assert.Equal(t, "range", check.Handle)
+	assert.Equal(t, []interface{}{uint8(1), uint8(150)}, check.Args)
+	assert.Error(t, check.Err)
+}
+
+func TestExplain_StopsAtFailure(t *testing.T) {
+	trace, err := Explain(&testExplainAddr{})
+	assert.EqualError(t, err, `Validation failed for field "City": should not be empty`)
+	assert.Equal(t, `validator.testExplainAddr
+  City
+    nonempty: fail: should not be empty
+`, trace.String())
+}
+
+func TestExplain_Fields(t *testing.T) {
+	trace, err := Explain(testExplain{Name: "foo"}, WithFields("Name"))
+	assert.NoError(t, err)
+	assert.Equal(t, `validator.testExplain
+  testExplainMeta
+    embedded validator.testExplainMeta
+      skip ID: not selected
+  Name
+    nonempty: pass
+    maxlen(5): pass
+  skip Nick: not selected
+  skip Age: not selected
+  skip Home: not selected
+  skip Work: not selected
+  skip Prev: not selected
+  skip Raw: not selected
+  skip Secret: not selected
+  skip Kind: not selected
+`, trace.String())
+}
@@ -0,0 +1,172 @@
+// Package expvarobserver exposes the validation metrics via expvar.
+package expvarobserver
+
+import (
+	"expvar"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/osdrv/validator"
+)
+
+// DefaultBuckets are the upper bounds of the duration histogram buckets.
+var DefaultBuckets = []time.Duration{
+	time.Microsecond,
+	10 * time.Microsecond,
+	100 * time.Microsecond,
+	time.Millisecond,
+	10 * time.Millisecond,
+	100 * time.Millisecond,
+	time.Second,
+}
+
+// Observer is a validator.Observer publishing an expvar.Map with:
+//
+//	validations          counter by the validated type
+//	validation_errors    counter of the failed validations by type
+//	validation_duration  histogram by type
+//	checks               counter of the validator calls by handle
+//	check_errors         counter of the failed validator calls by handle
+//	check_duration       histogram by handle
+type Observer struct {
+	vars              *expvar.Map
+	validations       *expvar.Map
+	validationErrors  *expvar.Map
+	validationLatency *expvar.Map
+	checks            *expvar.Map
+	checkErrors       *expvar.Map
+	checkLatency      *expvar.Map
+
+	buckets []time.Duration
+	// mu serializes the histogram creation
+	mu sync.Mutex
+}
+
+// New returns an Observer publishing its metrics under name. Like
+// expvar.Publish, it panics if name is already in use.
+func New(name string) *Observer {
+	o := newObserver(DefaultBuckets)
+	expvar.Publish(name, o.vars)
+	return o
+}
+
+func newObserver(buckets []time.Duration) *Observer {
+	o := &Observer{
+		vars:              new(expvar.Map).Init(),
+		validations:       new(expvar.Map).Init(),
+		validationErrors:  new(expvar.Map).Init(),
+		validationLatency: new(expvar.Map).Init(),
+		checks:            new(expvar.Map).Init(),
+		checkErrors:       new(expvar.Map).Init(),
+		checkLatency:      new(expvar.Map).Init(),
+		buckets:           buckets,
+	}
+	o.vars.Set("validations", o.validations)
+	o.vars.Set("validation_errors", o.validationErrors)
+	o.vars.Set("validation_duration", o.validationLatency)
+	o.vars.Set("checks", o.checks)
+	o.vars.Set("check_errors", o.checkErrors)
+	o.vars.Set("check_duration", o.checkLatency)
+	return o
+}
+
+var _ validator.Observer = (*Observer)(nil)
+
+// Vars returns the published map.
+func (o *Observer) Vars() *expvar.Map {
+	return o.vars
+}
+
+func (o *Observer) OnValidateStart(t reflect.Type) {}
+
+func (o *Observer) OnFieldCheck(c validator.FieldCheck) {
+	o.checks.Add(c.Handle, 1)
+	if c.Err != nil {
+		o.checkErrors.Add(c.Handle, 1)
+	}
+	o.histogram(o.checkLatency, c.Handle).Observe(c.Duration)
+}
+
+func (o *Observer) OnValidateEnd(t reflect.Type, d time.Duration, err error) {
+	name := typeName(t)
+	o.validations.Add(name, 1)
+	if err != nil {
+		o.validationErrors.Add(name, 1)
+	}
+	o.histogram(o.validationLatency, name).Observe(d)
+}
+
+func (o *Observer) histogram(m *expvar.Map, key string) *Histogram {
+	if h, ok := m.Get(key).(*Histogram); ok {
+		return h
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if h, ok := m.Get(key).(*Histogram); ok {
+		return h
+	}
+	h := NewHistogram(o.buckets)
+	m.Set(key, h)
+	return h
+}
+
+func typeName(t reflect.Type) string {
+	if t == nil {
+		return "nil"
+	}
+	return t.String()
+}
+
+// Histogram is an expvar.Var counting durations in cumulative buckets.
+type Histogram struct {
+	bounds []time.Duration
+	// counts has an extra bucket for the durations above the last bound
+	counts []int64
+	count  int64
+	sum    int64
+}
+
+func NewHistogram(bounds []time.Duration) *Histogram {
+	return &Histogram{
+		bounds: bounds,
+		counts: make([]int64, len(bounds)+1),
+	}
+}
+
+func (h *Histogram) Observe(d time.Duration) {
+	i := 0
+	for i < len(h.bounds) && d > h.bounds[i] {
+		i++
+	}
+	atomic.AddInt64(&h.counts[i], 1)
+	atomic.AddInt64(&h.count, 1)
+	atomic.AddInt64(&h.sum, int64(d))
+}
+
+// String renders the histogram as JSON, the sum in seconds and the buckets
+// keyed by their upper bounds, Prometheus style.
+func (h *Histogram) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `{"count": %d, "sum": %s, "buckets": {`,
+		atomic.LoadInt64(&h.count),
+		strconv.FormatFloat(time.Duration(atomic.LoadInt64(&h.sum)).Seconds(), 'g', -1, 64))
+	var cum int64
+	for i := range h.counts {
+		cum += atomic.LoadInt64(&h.counts[i])
+		le := "+Inf"
+		if i < len(h.bounds) {
+			le = strconv.FormatFloat(h.bounds[i].Seconds(), 'g', -1, 64)
+		}
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(&b, "%q: %d", le, cum)
+	}
+	b.WriteString("}}")
+	return b.String()
+}
@@ -0,0 +1,53 @@
+package expvarobserver
+
+import (
+	"encoding/json"
+	"expvar"
+	"testing"
+	"time"
+
+	"github.com/osdrv/validator"
+	"github.com/stretchr/testify/assert"
+)
+
+type testUser struct {
+	Name string `validate:"nonempty, maxlen(5)"`
+	Age  int    `validate:"range(0, 150)"`
+}
+
+func TestObserver(t *testing.T) {
+	o := New("test_validator")
+	assert.Same(t, o.Vars(), expvar.Get("test_validator"))
+
+	opt := validator.WithObserver(o)
+	assert.NoError(t, validator.Validate(testUser{Name: "foo", Age: 20}, opt))
+	assert.Error(t, validator.Validate(testUser{Name: "foobarbaz"}, opt))
+
+	var vars struct {
+		Validations      map[string]int64 `json:"validations"`
+		ValidationErrors map[string]int64 `json:"validation_errors"`
+		Checks           map[string]int64 `json:"checks"`
+		CheckErrors      map[string]int64 `json:"check_errors"`
+		CheckDuration    map[string]struct {
+			Count   int64            `json:"count"`
+			Buckets map[string]int64 `json:"buckets"`
+		} `json:"check_duration"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(o.Vars().String()), &vars))
+	assert.Equal(t, map[string]int64{"expvarobserver.testUser": 2}, vars.Validations)
+	assert.Equal(t, map[string]int64{"expvarobserver.testUser": 1}, vars.ValidationErrors)
+	assert.Equal(t, map[string]int64{"nonempty": 2, "maxlen": 2, "range": 1}, vars.Checks)
+	assert.Equal(t, map[string]int64{"maxlen": 1}, vars.CheckErrors)
+	assert.Equal(t, int64(2), vars.CheckDuration["maxlen"].Count)
+	assert.Equal(t, int64(2), vars.CheckDuration["maxlen"].Buckets["+Inf"])
+}
+
+func TestHistogram(t *testing.T) {
+	h := NewHistogram([]time.Duration{time.Millisecond, time.Second})
+	h.Observe(time.Microsecond)
+	h.Observe(time.Millisecond)
+	h.Observe(500 * time.Millisecond)
+	h.Observe(2 * time.Second)
+	assert.Equal(t, `{"count": 4, "sum": 2.501001, "buckets": {"0.001": 2, "1": 3, "+Inf": 4}}`, h.String())
+	assert.True(t, json.Valid([]byte(h.String())))
+}
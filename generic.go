@@ -0,0 +1,10 @@
+package validator
+
+// RegisterFunc is a typed counterpart of Register: the shape of check is
+// verified at compile time and the tag arguments are passed to it as is. The
+// tags are not typed though, so T is still matched against the type of the
+// field at run time: a field T can't be converted from fails the validation
+// with an argument conversion error.
+func RegisterFunc[T any](handle string, check func(T, ...string) error) error {
+	return Register(handle, check)
+}
@@ -0,0 +1,82 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterFunc(t *testing.T) {
+	type Slug string
+
+	err := RegisterFunc("generic_prefix", func(v Slug, prefixes ...string) error {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(string(v), prefix) {
+				return nil
+			}
+		}
+		return fmt.Errorf("should start with one of %v", prefixes)
+	})
+	assert.NoError(t, err)
+
+	err = RegisterFunc("generic_prefix", func(v string, _ ...string) error { return nil })
+	assert.Error(t, err)
+
+	type TestStruct struct {
+		Slug  Slug   `validate:"generic_prefix(foo, bar)"`
+		Plain string `validate:"optional, generic_prefix(baz)"`
+	}
+
+	err = Validate(TestStruct{Slug: "foo-1"})
+	assert.NoError(t, err)
+
+	err = Validate(&TestStruct{Slug: "baz-1"})
+	assert.Error(t, err)
+	assert.Equal(t, `Validation failed for field "Slug": should start with one of [foo bar]`, err.Error())
+
+	err = Validate(TestStruct{Slug: "bar-1", Plain: "bar-2"})
+	assert.Error(t, err)
+	assert.Equal(t, `Validation failed for field "Plain": should start with one of [baz]`, err.Error())
+
+	// T is matched against the field type at run time only
+	type Mismatched struct {
+		Count []int `validate:"generic_prefix(foo)"`
+	}
+	err = Validate(Mismatched{Count: []int{1}})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `Validation failed for field "Count": argument conversion failed`)
+}
+
+func TestConvArgV_NamedTypes(t *testing.T) {
+	type Age int
+	Register("generic_adult", func(v Age, min Age) (bool, string) {
+		return v >= min, fmt.Sprintf("should be at least %d", min)
+	})
+	Register("generic_ratio", func(v float32, max float32) bool {
+		return v <= max
+	})
+
+	type TestStruct struct {
+		Age   Age     `validate:"generic_adult(18)"`
+		Ratio float32 `validate:"generic_ratio(0.5)"`
+		Flag  bool    `validate:"optional, generic_adult(1)"`
+	}
+
+	var err error
+
+	err = Validate(TestStruct{Age: 21, Ratio: 0.25})
+	assert.NoError(t, err)
+
+	err = Validate(TestStruct{Age: 16})
+	assert.Error(t, err)
+	assert.Equal(t, `Validation failed for field "Age": should be at least 18`, err.Error())
+
+	err = Validate(TestStruct{Age: 21, Ratio: 0.75})
+	assert.Error(t, err)
+
+	err = Validate(TestStruct{Age: 21, Flag: true})
+	assert.Error(t, err)
+	assert.Equal(t, `Validation failed for field "Flag": argument conversion failed: cannot use bool as validator.Age`, err.Error())
+}
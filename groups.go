@@ -0,0 +1,82 @@
+package validator
+
+import (
+	"reflect"
+	"strings"
+)
+
+// DefaultGroup refers to the rules of the plain validate tag.
+const DefaultGroup = "default"
+
+// ValidateGroups validates datum against the rules of the given groups. A
+// group rule is declared with a `validate.<group>` tag next to the default
+// validate tag:
+//
+//	Id string `validate:"nonempty" validate.create:"empty"`
+//
+// A field declaring rules for any of the given groups is checked against the
+// rules of these groups only, the rest of the fields are checked against the
+// default rules. DefaultGroup can be listed along with other groups to always
+// check the default rules, too. The rules of each group form a chain of their
+// own, so a chain break, like optional on a zero value, does not skip the
+// rules of the other groups. The groups apply to the nested structs as well.
+func ValidateGroups(datum interface{}, groups ...string) error {
+	return Validate(datum, WithGroups(groups...))
+}
+
+func WithGroups(groups ...string) Option {
+	return func(o *options) {
+		o.groups = groups
+	}
+}
+
+// lookupRules returns the rule definitions of the groups applying to the
+// field.
+func (s *validation) lookupRules(tag reflect.StructTag) []string {
+	rules := make([]string, 0, len(s.groups))
+	found := false
+	for _, group := range s.groups {
+		if group == DefaultGroup {
+			if def, ok := tag.Lookup(ValidateTagName); ok && strings.TrimSpace(def) != "" {
+				rules = append(rules, def)
+			}
+			continue
+		}
+		if def, ok := tag.Lookup(ValidateTagName + "." + group); ok {
+			if strings.TrimSpace(def) != "" {
+				rules = append(rules, def)
+			}
+			found = true
+		}
+	}
+	if !found {
+		return []string{tag.Get(ValidateTagName)}
+	}
+	return rules
+}
+
+// parseGroupRules parses the rule definitions of several groups keeping the
+// chain of each group separate. A field is skipped if all the groups skip
+// it.
+func parseGroupRules(defs []string) (*ruleSet, error) {
+	if len(defs) <= 1 {
+		return parseRules(strings.Join(defs, ""))
+	}
+	rules := &ruleSet{skip: true}
+	for _, def := range defs {
+		r, err := parseRules(def)
+		if err != nil {
+			return nil, err
+		}
+		if r.skip {
+			continue
+		}
+		rules.skip = false
+		rules.tags = append(rules.tags, r.tags...)
+		rules.chains = append(rules.chains, r.tags)
+		rules.normalize = append(rules.normalize, r.normalize...)
+		rules.nodive = rules.nodive || r.nodive
+		rules.sensitive = rules.sensitive || r.sensitive
+	}
+	return rules, nil
+}
@@ -0,0 +1,142 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateGroups(t *testing.T) {
+	type Owner struct {
+		Id    string `validate:"nonempty" validate.create:"empty"`
+		Email string `validate:"nonempty" validate.admin:""`
+	}
+	type Message struct {
+		Id    string `validate:"nonempty" validate.create:"empty"`
+		Title string `validate:"nonempty" validate.admin:"maxlen(3)"`
+		Owner Owner
+	}
+
+	tests := []struct {
+		name     string
+		input    Message
+		groups   []string
+		wantPath string
+	}{
+		{
+			name:   "no groups",
+			input:  Message{Id: "1", Title: "foo", Owner: Owner{Id: "2", Email: "me@example.com"}},
+			groups: nil,
+		},
+		{
+			name:     "default rules",
+			input:    Message{Title: "foo"},
+			groups:   []string{DefaultGroup},
+			wantPath: "Id",
+		},
+		{
+			name:   "group rules override default rules",
+			input:  Message{Title: "foo", Owner: Owner{Email: "me@example.com"}},
+			groups: []string{"create"},
+		},
+		{
+			name:     "group rules apply to nested structs",
+			input:    Message{Title: "foo", Owner: Owner{Id: "2", Email: "me@example.com"}},
+			groups:   []string{"create"},
+			wantPath: "Owner.Id",
+		},
+		{
+			name:     "group rules fail",
+			input:    Message{Id: "1", Title: "foo"},
+			groups:   []string{"create"},
+			wantPath: "Id",
+		},
+		{
+			name:     "fields without group rules fall back to default rules",
+			input:    Message{Id: "1", Title: "foo"},
+			groups:   []string{"update"},
+			wantPath: "Owner.Id",
+		},
+		{
+			name:   "empty group rules disable default rules",
+			input:  Message{Id: "1", Title: "foo", Owner: Owner{Id: "2"}},
+			groups: []string{"admin"},
+		},
+		{
+			name:     "multiple groups",
+			input:    Message{Title: "foobar", Owner: Owner{}},
+			groups:   []string{"create", "admin"},
+			wantPath: "Title",
+		},
+		{
+			name:     "default group along with other groups",
+			input:    Message{Id: "1", Title: "foo", Owner: Owner{Id: "2"}},
+			groups:   []string{"admin", DefaultGroup},
+			wantPath: "Owner.Email",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateGroups(tt.input, tt.groups...)
+			if tt.wantPath == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			fe, ok := err.(*FieldError)
+			if assert.True(t, ok) {
+				assert.Equal(t, tt.wantPath, fe.Path)
+			}
+		})
+	}
+}
+
+func TestValidateGroups_SeparateChains(t *testing.T) {
+	type G struct {
+		Name string `validate:"optional, maxlen(5)" validate.update:"nonempty"`
+		Note string `validate:"-" validate.update:"optional, maxlen(3)"`
+	}
+
+	tests := []struct {
+		name    string
+		input   G
+		groups  []string
+		wantErr string
+	}{
+		{
+			name:    "a chain break does not skip the other groups",
+			input:   G{},
+			groups:  []string{DefaultGroup, "update"},
+			wantErr: `Validation failed for field "Name": should not be empty`,
+		},
+		{
+			name:    "each chain applies",
+			input:   G{Name: "foobar"},
+			groups:  []string{DefaultGroup, "update"},
+			wantErr: `Validation failed for field "Name": length must be up to 5`,
+		},
+		{
+			name:   "valid",
+			input:  G{Name: "foo", Note: "ab"},
+			groups: []string{DefaultGroup, "update"},
+		},
+		{
+			name:    "a skipping group does not skip the others",
+			input:   G{Name: "foo", Note: "abcd"},
+			groups:  []string{DefaultGroup, "update"},
+			wantErr: `Validation failed for field "Note": length must be up to 3`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateGroups(tt.input, tt.groups...)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,289 @@
+// Package httpvalidate decodes and validates HTTP requests, reporting the
+// failures as RFC 9457 problem details.
+package httpvalidate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/osdrv/validator"
+	"github.com/osdrv/validator/internal/convert"
+)
+
+const (
+	QueryTagName = "query"
+	FormTagName  = "form"
+
+	ProblemContentType = "application/problem+json"
+
+	// DefaultMaxBodySize is the request body limit unless WithMaxBodySize
+	// sets another one.
+	DefaultMaxBodySize = 1 << 20
+)
+
+type Option func(*options)
+
+type options struct {
+	maxBodySize int64
+	validate    []validator.Option
+}
+
+func newOptions(opts ...Option) *options {
+	o := &options{maxBodySize: DefaultMaxBodySize}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithMaxBodySize limits the request body to n bytes, a larger body is
+// answered with 413 Request Entity Too Large. A non-positive n lifts the
+// limit.
+func WithMaxBodySize(n int64) Option {
+	return func(o *options) {
+		o.maxBodySize = n
+	}
+}
+
+// WithValidatorOptions passes opts to the validation.
+func WithValidatorOptions(opts ...validator.Option) Option {
+	return func(o *options) {
+		o.validate = opts
+	}
+}
+
+// Problem is an RFC 9457 problem details object.
+type Problem struct {
+	Type   string         `json:"type"`
+	Title  string         `json:"title"`
+	Status int            `json:"status"`
+	Detail string         `json:"detail,omitempty"`
+	Errors []InvalidField `json:"errors,omitempty"`
+}
+
+// InvalidField describes a failed field of the request body by its JSON
+// pointer, or a failed query or form parameter by its name.
+type InvalidField struct {
+	Pointer   string `json:"pointer,omitempty"`
+	Parameter string `json:"parameter,omitempty"`
+	Detail    string `json:"detail"`
+}
+
+func NewProblem(status int, detail string) *Problem {
+	return &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+func (p *Problem) Error() string {
+	return p.Detail
+}
+
+func (p *Problem) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
+
+// Handler decodes the request into a new T, validates it with every error
+// collected and calls fn with the result. A request failing the decoding or
+// the validation is answered with a problem+json response.
+func Handler[T any](fn func(w http.ResponseWriter, r *http.Request, v *T), opts ...Option) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		v := new(T)
+		if err := Decode(w, r, v, opts...); err != nil {
+			problemOf(err).ServeHTTP(w, r)
+			return
+		}
+		fn(w, r, v)
+	})
+}
+
+type valueKey struct{}
+
+// Middleware is the Handler counterpart for the handler chains: the decoded
+// value is passed on in the request context, see Value.
+func Middleware[T any](opts ...Option) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return Handler(func(w http.ResponseWriter, r *http.Request, v *T) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), valueKey{}, v)))
+		}, opts...)
+	}
+}
+
+// Value returns the value decoded by Middleware.
+func Value[T any](r *http.Request) (*T, bool) {
+	v, ok := r.Context().Value(valueKey{}).(*T)
+	return v, ok
+}
+
+// Decode decodes the request into the struct pointed to by v and validates
+// it. The JSON body is decoded into v; a form body and the query string fill
+// the top-level fields tagged with form and query. The body is limited to
+// DefaultMaxBodySize bytes unless WithMaxBodySize says otherwise, w is told
+// to close the connection when the limit is hit. A malformed or invalid
+// request is reported with a *Problem.
+func Decode(w http.ResponseWriter, r *http.Request, v interface{}, opts ...Option) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("Decode accepts a pointer to a struct, %T given", v)
+	}
+	o := newOptions(opts...)
+	var body *countingReader
+	if r.Body != nil && o.maxBodySize > 0 {
+		body = &countingReader{ReadCloser: r.Body}
+		r.Body = http.MaxBytesReader(w, body, o.maxBodySize)
+	}
+	// bodyProblem reports a body that can't be decoded, MaxBytesReader reads
+	// past the limit only when the body is over it
+	bodyProblem := func(what string, err error) *Problem {
+		if body != nil && body.n > o.maxBodySize {
+			return NewProblem(http.StatusRequestEntityTooLarge, fmt.Sprintf("Request body exceeds %d bytes", o.maxBodySize))
+		}
+		return NewProblem(http.StatusBadRequest, fmt.Sprintf("%s: %s", what, err))
+	}
+	params := make(map[string]string)
+	var invalid []InvalidField
+
+	ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch {
+	case ct == "application/x-www-form-urlencoded" || ct == "multipart/form-data":
+		// ParseMultipartForm hides the ParseForm errors of a urlencoded body
+		parse := r.ParseForm
+		if ct == "multipart/form-data" {
+			parse = func() error { return r.ParseMultipartForm(32 << 20) }
+		}
+		if err := parse(); err != nil {
+			return bodyProblem("Malformed form", err)
+		}
+		invalid = append(invalid, decodeValues(rv.Elem(), FormTagName, r.PostForm, params)...)
+	case ct == "" || ct == "application/json" || strings.HasSuffix(ct, "+json"):
+		if r.Body == nil {
+			break
+		}
+		if err := decodeJSON(r.Body, v); err != nil {
+			return bodyProblem("Malformed JSON body", err)
+		}
+	default:
+		return NewProblem(http.StatusUnsupportedMediaType, fmt.Sprintf("Unsupported content type %q", ct))
+	}
+	invalid = append(invalid, decodeValues(rv.Elem(), QueryTagName, r.URL.Query(), params)...)
+	if len(invalid) > 0 {
+		p := NewProblem(http.StatusBadRequest, "Malformed request parameters")
+		p.Errors = invalid
+		return p
+	}
+
+	err := validator.ValidateCtx(r.Context(), v, append(o.validate, validator.WithAllErrors())...)
+	if err == nil {
+		return nil
+	}
+	// Only the failed constraints are collected, any other error, e.g. a
+	// validator that can't be applied to the field, is a server error
+	var errs validator.ValidationErrors
+	var de *validator.DepthError
+	switch {
+	case errors.As(err, &errs):
+	case errors.As(err, &de):
+		p := NewProblem(http.StatusBadRequest, "Request validation failed")
+		p.Errors = []InvalidField{{
+			Pointer: de.Pointer,
+			Detail:  fmt.Sprintf("nesting exceeds the maximum depth of %d", de.MaxDepth),
+		}}
+		return p
+	default:
+		return err
+	}
+	p := NewProblem(http.StatusBadRequest, "Request validation failed")
+	for _, fe := range errs {
+		p.Errors = append(p.Errors, invalidField(fe, params))
+	}
+	return p
+}
+
+// decodeJSON decodes a single JSON value from body into v, an empty body
+// leaves v as is.
+func decodeJSON(body io.Reader, v interface{}) error {
+	dec := json.NewDecoder(body)
+	if err := dec.Decode(v); err != nil {
+		if err == io.EOF {
+			return nil
+		}
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		if err != nil {
+			return err
+		}
+		return errors.New("unexpected data after the JSON value")
+	}
+	return nil
+}
+
+// countingReader counts the bytes read from the request body.
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// invalidField refers to a field filled from a parameter by the parameter
+// name, to any other field by its JSON pointer.
+func invalidField(fe *validator.FieldError, params map[string]string) InvalidField {
+	root := fe.Path
+	if i := strings.IndexAny(root, ".["); i >= 0 {
+		root = root[:i]
+	}
+	if name, ok := params[root]; ok {
+		return InvalidField{Parameter: name + strings.TrimPrefix(fe.Path, root), Detail: fe.Message}
+	}
+	return InvalidField{Pointer: fe.Pointer, Detail: fe.Message}
+}
+
+func problemOf(err error) *Problem {
+	var p *Problem
+	if errors.As(err, &p) {
+		return p
+	}
+	// not the client's fault, e.g. an unknown validator
+	return NewProblem(http.StatusInternalServerError, "")
+}
+
+// decodeValues sets the fields of v tagged with tagName from values and
+// records the parameter names of the fields in params.
+func decodeValues(v reflect.Value, tagName string, values url.Values, params map[string]string) []InvalidField {
+	var invalid []InvalidField
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get(tagName), ",")[0]
+		if name == "" || name == "-" || field.PkgPath != "" {
+			continue
+		}
+		params[field.Name] = name
+		vals, ok := values[name]
+		if !ok {
+			continue
+		}
+		if err := convert.SetValues(v.Field(i), vals); err != nil {
+			invalid = append(invalid, InvalidField{Parameter: name, Detail: err.Error()})
+		}
+	}
+	return invalid
+}
@@ -0,0 +1,271 @@
+package httpvalidate
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/osdrv/validator"
+	"github.com/stretchr/testify/assert"
+)
+
+type testItem struct {
+	SKU string `json:"sku" validate:"nonempty"`
+	Qty int    `json:"qty" validate:"range(1, 10)"`
+}
+
+type testOrder struct {
+	Customer string     `json:"customer" validate:"nonempty"`
+	Items    []testItem `json:"items" validate:"minitems(1)"`
+	Limit    int        `query:"limit" validate:"optional, lte(100)"`
+	Tags     []string   `query:"tag" validate:"optional, maxitems(2)"`
+	Since    *time.Time `query:"since"`
+}
+
+type testLogin struct {
+	User     string `form:"user" validate:"nonempty"`
+	Password string `form:"password" validate:"minlen(8)"`
+}
+
+func TestHandler(t *testing.T) {
+	var got *testOrder
+	h := Handler(func(w http.ResponseWriter, r *http.Request, v *testOrder) {
+		got = v
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		name        string
+		query       string
+		body        string
+		contentType string
+		wantStatus  int
+		wantProblem string
+	}{
+		{
+			name:       "valid",
+			query:      "limit=10&tag=a&tag=b&since=2024-01-02T03:04:05Z",
+			body:       `{"customer": "bob", "items": [{"sku": "a", "qty": 1}]}`,
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "invalid body",
+			body:       `{"items": [{"sku": "a", "qty": 1}, {"qty": 11}]}`,
+			wantStatus: http.StatusBadRequest,
+			wantProblem: `{"type": "about:blank", "title": "Bad Request", "status": 400, "detail": "Request validation failed",
+				"errors": [
+					{"pointer": "/customer", "detail": "should not be empty"},
+					{"pointer": "/items/1/sku", "detail": "should not be empty"},
+					{"pointer": "/items/1/qty", "detail": "should be in the range [1, 10]"}
+				]}`,
+		},
+		{
+			name:       "invalid query",
+			query:      "limit=1000&tag=a&tag=b&tag=c",
+			body:       `{"customer": "bob", "items": [{"sku": "a", "qty": 1}]}`,
+			wantStatus: http.StatusBadRequest,
+			wantProblem: `{"type": "about:blank", "title": "Bad Request", "status": 400, "detail": "Request validation failed",
+				"errors": [
+					{"parameter": "limit", "detail": "should be less or equal to 100"},
+					{"parameter": "tag", "detail": "should contain at most 2 items"}
+				]}`,
+		},
+		{
+			name:       "malformed query",
+			query:      "limit=ten&since=yesterday",
+			body:       `{"customer": "bob", "items": [{"sku": "a", "qty": 1}]}`,
+			wantStatus: http.StatusBadRequest,
+			wantProblem: `{"type": "about:blank", "title": "Bad Request", "status": 400, "detail": "Malformed request parameters",
+				"errors": [
+					{"parameter": "limit", "detail": "invalid value \"ten\", want int"},
+					{"parameter": "since", "detail": "invalid value \"yesterday\": parsing time \"yesterday\" as \"2006-01-02T15:04:05Z07:00\": cannot parse \"yesterday\" as \"2006\""}
+				]}`,
+		},
+		{
+			name:       "malformed body",
+			body:       `{"customer": `,
+			wantStatus: http.StatusBadRequest,
+			wantProblem: `{"type": "about:blank", "title": "Bad Request", "status": 400,
+				"detail": "Malformed JSON body: unexpected EOF"}`,
+		},
+		{
+			name:       "trailing data",
+			body:       `{"customer": "bob", "items": [{"sku": "a", "qty": 1}]} garbage`,
+			wantStatus: http.StatusBadRequest,
+			wantProblem: `{"type": "about:blank", "title": "Bad Request", "status": 400,
+				"detail": "Malformed JSON body: invalid character 'g' looking for beginning of value"}`,
+		},
+		{
+			name:       "second JSON value",
+			body:       `{"customer": "bob", "items": [{"sku": "a", "qty": 1}]} {}`,
+			wantStatus: http.StatusBadRequest,
+			wantProblem: `{"type": "about:blank", "title": "Bad Request", "status": 400,
+				"detail": "Malformed JSON body: unexpected data after the JSON value"}`,
+		},
+		{
+			name:        "unsupported content type",
+			body:        `customer`,
+			contentType: "text/plain",
+			wantStatus:  http.StatusUnsupportedMediaType,
+			wantProblem: `{"type": "about:blank", "title": "Unsupported Media Type", "status": 415,
+				"detail": "Unsupported content type \"text/plain\""}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = nil
+			req := httptest.NewRequest(http.MethodPost, "/orders?"+tt.query, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantProblem == "" {
+				assert.NotNil(t, got)
+				return
+			}
+			assert.Nil(t, got)
+			assert.Equal(t, ProblemContentType, rec.Header().Get("Content-Type"))
+			assert.JSONEq(t, tt.wantProblem, rec.Body.String())
+		})
+	}
+}
+
+func TestHandler_Decoded(t *testing.T) {
+	var got *testOrder
+	h := Handler(func(w http.ResponseWriter, r *http.Request, v *testOrder) {
+		got = v
+	})
+	req := httptest.NewRequest(http.MethodPost, "/orders?limit=10&tag=a&tag=b&since=2024-01-02T03:04:05Z",
+		strings.NewReader(`{"customer": "bob", "items": [{"sku": "a", "qty": 1}]}`))
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	since := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	assert.Equal(t, &testOrder{
+		Customer: "bob",
+		Items:    []testItem{{SKU: "a", Qty: 1}},
+		Limit:    10,
+		Tags:     []string{"a", "b"},
+		Since:    &since,
+	}, got)
+}
+
+func TestMiddleware_Form(t *testing.T) {
+	var got *testLogin
+	h := Middleware[testLogin]()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = Value[testLogin](r)
+	}))
+
+	form := url.Values{"user": {"bob"}, "password": {"secret"}}
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	var p Problem
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &p))
+	assert.Equal(t, []InvalidField{{Parameter: "password", Detail: "length must be at least 8"}}, p.Errors)
+	assert.Nil(t, got)
+
+	form.Set("password", "secret123")
+	req = httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, &testLogin{User: "bob", Password: "secret123"}, got)
+}
+
+func TestHandler_BodySize(t *testing.T) {
+	h := Handler(func(w http.ResponseWriter, r *http.Request, v *testOrder) {}, WithMaxBodySize(64))
+	body := `{"customer": "bob", "items": [{"sku": "a", "qty": 1}]}`
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body+strings.Repeat(" ", 64))))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	assert.JSONEq(t, `{"type": "about:blank", "title": "Request Entity Too Large", "status": 413,
+		"detail": "Request body exceeds 64 bytes"}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	form := "user=" + strings.Repeat("a", 64)
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(form))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	Handler(func(w http.ResponseWriter, r *http.Request, v *testLogin) {}, WithMaxBodySize(64)).ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+}
+
+type testEvent struct {
+	Payload interface{} `json:"payload"`
+	Parent  *testEvent  `json:"parent"`
+}
+
+func TestHandler_ClientErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		opts        []Option
+		wantProblem string
+	}{
+		{
+			name: "dynamic type not allowed",
+			body: `{"payload": 1}`,
+			opts: []Option{WithValidatorOptions(validator.WithAllowedTypes((*interface{})(nil), map[string]interface{}{}))},
+			wantProblem: `{"type": "about:blank", "title": "Bad Request", "status": 400, "detail": "Request validation failed",
+				"errors": [{"pointer": "/payload", "detail": "dynamic type float64 is not allowed"}]}`,
+		},
+		{
+			name: "too deep",
+			body: `{"parent": {"parent": {"parent": {}}}}`,
+			opts: []Option{WithValidatorOptions(validator.WithMaxDepth(2))},
+			wantProblem: `{"type": "about:blank", "title": "Bad Request", "status": 400, "detail": "Request validation failed",
+				"errors": [{"pointer": "/parent/parent", "detail": "nesting exceeds the maximum depth of 2"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := Handler(func(w http.ResponseWriter, r *http.Request, v *testEvent) {}, tt.opts...)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/events", strings.NewReader(tt.body)))
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.JSONEq(t, tt.wantProblem, rec.Body.String())
+		})
+	}
+}
+
+func TestHandler_ServerError(t *testing.T) {
+	type Broken struct {
+		Name string `validate:"no_such_validator"`
+	}
+	h := Handler(func(w http.ResponseWriter, r *http.Request, v *Broken) {})
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.JSONEq(t, `{"type": "about:blank", "title": "Internal Server Error", "status": 500}`, rec.Body.String())
+
+	// a tag that does not apply to the field is not the client's fault
+	type Misused struct {
+		Name string `json:"name" validate:"nonempty"`
+		Age  int    `json:"age" validate:"maxlen(3)"`
+	}
+	rec = httptest.NewRecorder()
+	Handler(func(w http.ResponseWriter, r *http.Request, v *Misused) {}).
+		ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"age": 1}`)))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.JSONEq(t, `{"type": "about:blank", "title": "Internal Server Error", "status": 500}`, rec.Body.String())
+}
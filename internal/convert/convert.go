@@ -0,0 +1,98 @@
+// Package convert sets struct fields from their string representations.
+package convert
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	durationType        = reflect.TypeOf(time.Duration(0))
+)
+
+// SetValues sets v from vals. A slice gets an element per value, any other
+// type is set from the last value.
+func SetValues(v reflect.Value, vals []string) error {
+	if reflect.PtrTo(v.Type()).Implements(textUnmarshalerType) {
+		return SetString(v, vals[len(vals)-1])
+	}
+	switch v.Kind() {
+	case reflect.Slice:
+		s := reflect.MakeSlice(v.Type(), len(vals), len(vals))
+		for i, val := range vals {
+			if err := SetString(s.Index(i), val); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+		return nil
+	case reflect.Ptr:
+		p := reflect.New(v.Type().Elem())
+		if err := SetValues(p.Elem(), vals); err != nil {
+			return err
+		}
+		v.Set(p)
+		return nil
+	}
+	return SetString(v, vals[len(vals)-1])
+}
+
+// SetString sets v from s. Besides the basic kinds it supports
+// time.Duration, pointers and the encoding.TextUnmarshaler implementations.
+func SetString(v reflect.Value, s string) error {
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		if err := u.UnmarshalText([]byte(s)); err != nil {
+			return fmt.Errorf("invalid value %q: %s", s, err)
+		}
+		return nil
+	}
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("invalid value %q, want a duration", s)
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	var err error
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(s); err == nil {
+			v.SetBool(b)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		if n, err = strconv.ParseInt(s, 10, v.Type().Bits()); err == nil {
+			v.SetInt(n)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var n uint64
+		if n, err = strconv.ParseUint(s, 10, v.Type().Bits()); err == nil {
+			v.SetUint(n)
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(s, v.Type().Bits()); err == nil {
+			v.SetFloat(f)
+		}
+	case reflect.Ptr:
+		p := reflect.New(v.Type().Elem())
+		if err := SetString(p.Elem(), s); err != nil {
+			return err
+		}
+		v.Set(p)
+	default:
+		return fmt.Errorf("unsupported field type %v", v.Type())
+	}
+	if err != nil {
+		return fmt.Errorf("invalid value %q, want %v", s, v.Type())
+	}
+	return nil
+}
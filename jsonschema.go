@@ -0,0 +1,666 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// SchemaFunc contributes the constraints of a validator to the JSON Schema
+// of a value of type t. args are the validator arguments from the tag.
+type SchemaFunc func(schema map[string]interface{}, t reflect.Type, args ...string) error
+
+var schemaFuncs = make(map[string]SchemaFunc)
+
+func init() {
+	RegisterSchema("contains", schemaContains)
+	RegisterSchema("empty", schemaEmpty)
+	RegisterSchema("enum", schemaEnum)
+	RegisterSchema("eq", schemaEq)
+	RegisterSchema("gt", schemaBound("exclusiveMinimum"))
+	RegisterSchema("gte", schemaBound("minimum"))
+	RegisterSchema("len", schemaLen)
+	RegisterSchema("lt", schemaBound("exclusiveMaximum"))
+	RegisterSchema("lte", schemaBound("maximum"))
+	RegisterSchema("maxitems", schemaItems("maxItems", "maxProperties"))
+	RegisterSchema("maxlen", schemaMaxLen)
+	RegisterSchema("maxrunes", schemaMaxLen)
+	RegisterSchema("minitems", schemaItems("minItems", "minProperties"))
+	RegisterSchema("minlen", schemaMinLen)
+	RegisterSchema("minrunes", schemaMinLen)
+	RegisterSchema("ne", schemaNe)
+	RegisterSchema("nonempty", schemaNonEmpty)
+	RegisterSchema("range", schemaRange)
+	RegisterSchema("subset", schemaSubset)
+	RegisterSchema("unique", schemaUnique)
+}
+
+// RegisterSchema registers the JSON Schema counterpart of the validator
+// registered under the same handle.
+func RegisterSchema(handle string, fn SchemaFunc) error {
+	if _, ok := schemaFuncs[handle]; ok {
+		return duplicateValidatorDefErr(handle)
+	}
+	schemaFuncs[handle] = fn
+	return nil
+}
+
+// JSONSchema builds a draft 2020-12 JSON Schema for the type t from the
+// validate tags. Struct fields are named after their json tags; a field is
+// required unless its chain contains optional; a field skipped with "-" is
+// described without constraints. Nested named structs are placed in $defs.
+func JSONSchema(t reflect.Type) (map[string]interface{}, error) {
+	g := newSchemaGen("#/$defs/")
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	var schema map[string]interface{}
+	var err error
+	if t.Kind() == reflect.Struct {
+		g.refs[t] = "#"
+		schema, err = g.structSchema(t)
+	} else {
+		schema, err = g.typeSchema(t)
+	}
+	if err != nil {
+		return nil, err
+	}
+	res := map[string]interface{}{
+		"$schema": JSONSchemaDraft,
+	}
+	for k, v := range schema {
+		res[k] = v
+	}
+	if len(g.defs) > 0 {
+		res["$defs"] = g.defs
+	}
+	return res, nil
+}
+
+// SchemaOption configures JSONSchemaDefs.
+type SchemaOption func(*schemaGen)
+
+// WithSchemaRefPrefix sets the prefix of the references to the generated
+// definitions, "#/$defs/" by default.
+func WithSchemaRefPrefix(prefix string) SchemaOption {
+	return func(g *schemaGen) {
+		g.refPrefix = prefix
+	}
+}
+
+// WithSchemaDocs sets the descriptions of the types and fields keyed by
+// "Type" and "Type.Field", qualified with the package path, e.g.
+// "example.com/api.User.Email", or bare. A bare key naming a type shared by
+// several of the generated types is rejected.
+func WithSchemaDocs(docs map[string]string) SchemaOption {
+	return func(g *schemaGen) {
+		g.docs = docs
+	}
+}
+
+// WithSchemaUnmapped sets the func called for a validator without a
+// registered SchemaFunc, which are left out of the schema otherwise.
+func WithSchemaUnmapped(fn func(schema map[string]interface{}, tag ValidateTag)) SchemaOption {
+	return func(g *schemaGen) {
+		g.unmapped = fn
+	}
+}
+
+// JSONSchemaDefs builds the schemas of the named struct types and of the
+// named structs they refer to, keyed by the type name. It is the building
+// block for the documents embedding the schemas, like OpenAPI.
+func JSONSchemaDefs(types []reflect.Type, opts ...SchemaOption) (map[string]interface{}, error) {
+	g := newSchemaGen("#/$defs/")
+	for _, opt := range opts {
+		opt(g)
+	}
+	for _, t := range types {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct || t.Name() == "" {
+			return nil, fmt.Errorf("Schema definitions are generated for named struct types, %v given", t)
+		}
+		if _, err := g.structRef(t); err != nil {
+			return nil, fmt.Errorf("%v: %s", t, err)
+		}
+	}
+	if err := g.checkDocs(); err != nil {
+		return nil, err
+	}
+	return g.defs, nil
+}
+
+type schemaGen struct {
+	refPrefix string
+	refs      map[reflect.Type]string
+	defs      map[string]interface{}
+	// unmapped is called for a validator without a registered SchemaFunc.
+	unmapped func(schema map[string]interface{}, tag ValidateTag)
+	// docs holds descriptions keyed by "Type" and "Type.Field", qualified
+	// with the package path or bare.
+	docs map[string]string
+	// named counts the generated types by their bare name
+	named map[string]int
+}
+
+func newSchemaGen(refPrefix string) *schemaGen {
+	return &schemaGen{
+		refPrefix: refPrefix,
+		refs:      make(map[reflect.Type]string),
+		defs:      make(map[string]interface{}),
+		named:     make(map[string]int),
+	}
+}
+
+// doc returns the description of the named type t, or of its field if
+// field is not empty.
+func (g *schemaGen) doc(t reflect.Type, field string) (string, bool) {
+	if t.Name() == "" {
+		return "", false
+	}
+	key := t.Name()
+	if field != "" {
+		key += "." + field
+	}
+	if doc, ok := g.docs[t.PkgPath()+"."+key]; ok {
+		return doc, true
+	}
+	doc, ok := g.docs[key]
+	return doc, ok
+}
+
+// checkDocs rejects the bare doc keys of a name shared by several types,
+// which can't tell them apart.
+func (g *schemaGen) checkDocs() error {
+	keys := make([]string, 0, len(g.docs))
+	for key := range g.docs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		name := key
+		if ix := strings.IndexByte(key, '.'); ix >= 0 {
+			name = key[:ix]
+		}
+		if g.named[name] > 1 {
+			return fmt.Errorf("Doc key %q is ambiguous: %d types are named %s, qualify it with the package path", key, g.named[name], name)
+		}
+	}
+	return nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func (g *schemaGen) typeSchema(t reflect.Type) (map[string]interface{}, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}, nil
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		return map[string]interface{}{"type": "string", "contentEncoding": "base64"}, nil
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return map[string]interface{}{"type": "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}, nil
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}, nil
+	case reflect.Slice, reflect.Array:
+		items, err := g.typeSchema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": "array", "items": items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported map key type: %v", t.Key())
+		}
+		props, err := g.typeSchema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": "object", "additionalProperties": props}, nil
+	case reflect.Interface:
+		return map[string]interface{}{}, nil
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return g.structRef(t)
+	}
+	return nil, fmt.Errorf("unsupported type: %v", t)
+}
+
+func (g *schemaGen) structRef(t reflect.Type) (map[string]interface{}, error) {
+	if ref, ok := g.refs[t]; ok {
+		return map[string]interface{}{"$ref": ref}, nil
+	}
+	name := t.Name()
+	g.named[name]++
+	if _, ok := g.defs[name]; ok {
+		name = strings.ReplaceAll(t.PkgPath(), "/", ".") + "." + name
+	}
+	g.refs[t] = g.refPrefix + name
+	// reserve the name for recursive types
+	g.defs[name] = nil
+	schema, err := g.structSchema(t)
+	if err != nil {
+		return nil, err
+	}
+	g.defs[name] = schema
+	return map[string]interface{}{"$ref": g.refs[t]}, nil
+}
+
+func (g *schemaGen) structSchema(t reflect.Type) (map[string]interface{}, error) {
+	props := make(map[string]interface{})
+	required := []string{}
+	if err := g.structFields(t, props, &required); err != nil {
+		return nil, err
+	}
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": props,
+	}
+	if doc, ok := g.doc(t, ""); ok {
+		schema["description"] = doc
+	}
+	if len(required) > 0 {
+		sort.Strings(required)
+		schema["required"] = required
+	}
+	return schema, nil
+}
+
+// schemaField is a struct field at the embedding depth it is promoted from.
+type schemaField struct {
+	field reflect.StructField
+	owner reflect.Type
+	rules *ruleSet
+	depth int
+}
+
+func (g *schemaGen) structFields(t reflect.Type, props map[string]interface{}, required *[]string) error {
+	var fields []schemaField
+	if err := collectFields(t, 0, &fields); err != nil {
+		return err
+	}
+	for _, f := range dominantFields(fields) {
+		name := jsonName(f.field)
+		schema, err := g.fieldSchema(f.field.Type, f.rules)
+		if err != nil {
+			return fmt.Errorf("field %q: %s", f.field.Name, err)
+		}
+		if doc, ok := g.doc(f.owner, f.field.Name); ok {
+			schema["description"] = doc
+		}
+		props[name] = schema
+		if !f.rules.skip && !f.rules.has("optional") {
+			*required = append(*required, name)
+		}
+	}
+	return nil
+}
+
+// collectFields lists the fields of t along with the fields promoted from
+// its embedded structs.
+func collectFields(t reflect.Type, depth int, fields *[]schemaField) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		if strings.Split(field.Tag.Get("json"), ",")[0] == "-" {
+			continue
+		}
+		rules, err := parseRules(field.Tag.Get(ValidateTagName))
+		if err != nil {
+			return err
+		}
+		if isPromoted(field) && !rules.skip {
+			ft := field.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if err := collectFields(ft, depth+1, fields); err != nil {
+				return err
+			}
+			continue
+		}
+		*fields = append(*fields, schemaField{field: field, owner: t, rules: rules, depth: depth})
+	}
+	return nil
+}
+
+// dominantFields resolves the fields sharing a JSON name the way
+// encoding/json does: the shallowest field wins, a tagged field wins over
+// the untagged ones at the same depth and the name is dropped if it is still
+// ambiguous.
+func dominantFields(fields []schemaField) []schemaField {
+	byName := make(map[string][]int)
+	for i, f := range fields {
+		name := jsonName(f.field)
+		byName[name] = append(byName[name], i)
+	}
+	res := make([]schemaField, 0, len(byName))
+	for i, f := range fields {
+		if dominantField(fields, byName[jsonName(f.field)]) == i {
+			res = append(res, f)
+		}
+	}
+	return res
+}
+
+// dominantField returns the index of the dominant field among the
+// candidates, or -1.
+func dominantField(fields []schemaField, cands []int) int {
+	depth := fields[cands[0]].depth
+	for _, i := range cands {
+		if fields[i].depth < depth {
+			depth = fields[i].depth
+		}
+	}
+	top, tagged := -1, -1
+	ntop, ntagged := 0, 0
+	for _, i := range cands {
+		if fields[i].depth != depth {
+			continue
+		}
+		top = i
+		ntop++
+		if _, ok := fields[i].field.Tag.Lookup("json"); ok {
+			tagged = i
+			ntagged++
+		}
+	}
+	switch {
+	case ntop == 1:
+		return top
+	case ntagged == 1:
+		return tagged
+	}
+	return -1
+}
+
+func (g *schemaGen) fieldSchema(t reflect.Type, rules *ruleSet) (map[string]interface{}, error) {
+	schema, err := g.typeSchema(t)
+	if err != nil {
+		return nil, err
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	for _, tag := range rules.tags {
+		fn, ok := schemaFuncs[tag.Op]
+		if !ok {
+			if g.unmapped != nil {
+				g.unmapped(schema, tag)
+			}
+			continue
+		}
+		args := make([]string, 0, len(tag.Args))
+		for _, arg := range tag.Args {
+			args = append(args, fmt.Sprint(arg))
+		}
+		if err := fn(schema, t, args...); err != nil {
+			return nil, fmt.Errorf("%s: %s", tag.Op, err)
+		}
+	}
+	for _, tag := range rules.normalize {
+		if tag.Op != "default" || len(tag.Args) != 1 {
+			continue
+		}
+		def, err := schemaValue(t, fmt.Sprint(tag.Args[0]))
+		if err != nil {
+			return nil, fmt.Errorf("default: %s", err)
+		}
+		schema["default"] = def
+	}
+	return schema, nil
+}
+
+func (r *ruleSet) has(op string) bool {
+	for _, tag := range r.tags {
+		if tag.Op == op {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaValue converts a tag argument to a JSON value of the type t.
+func schemaValue(t reflect.Type, arg string) (interface{}, error) {
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		v, err := convStringVal(arg, t.Kind())
+		if err != nil {
+			return nil, err
+		}
+		return v.Interface(), nil
+	}
+	return arg, nil
+}
+
+func schemaValues(t reflect.Type, args []string) ([]interface{}, error) {
+	vals := make([]interface{}, 0, len(args))
+	for _, arg := range args {
+		v, err := schemaValue(t, arg)
+		if err != nil {
+			return nil, err
+		}
+		vals = append(vals, v)
+	}
+	return vals, nil
+}
+
+func wantArgs(args []string, n int) error {
+	if len(args) != n {
+		return fmt.Errorf("want %d arguments, got %d", n, len(args))
+	}
+	return nil
+}
+
+func isNumeric(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func schemaMaxLen(schema map[string]interface{}, t reflect.Type, args ...string) error {
+	if err := wantArgs(args, 1); err != nil {
+		return err
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil {
+		return err
+	}
+	schema["maxLength"] = n
+	return nil
+}
+
+func schemaMinLen(schema map[string]interface{}, t reflect.Type, args ...string) error {
+	if err := wantArgs(args, 1); err != nil {
+		return err
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil {
+		return err
+	}
+	schema["minLength"] = n
+	return nil
+}
+
+func schemaLen(schema map[string]interface{}, t reflect.Type, args ...string) error {
+	if err := schemaMaxLen(schema, t, args...); err != nil {
+		return err
+	}
+	schema["minLength"] = schema["maxLength"]
+	return nil
+}
+
+func schemaEmpty(schema map[string]interface{}, t reflect.Type, args ...string) error {
+	switch t.Kind() {
+	case reflect.String:
+		schema["maxLength"] = 0
+	case reflect.Slice, reflect.Array:
+		schema["maxItems"] = 0
+	case reflect.Map:
+		schema["maxProperties"] = 0
+	default:
+		if isNumeric(t) {
+			schema["const"] = 0
+		}
+	}
+	return nil
+}
+
+func schemaNonEmpty(schema map[string]interface{}, t reflect.Type, args ...string) error {
+	switch t.Kind() {
+	case reflect.String:
+		schema["minLength"] = 1
+	case reflect.Slice, reflect.Array:
+		schema["minItems"] = 1
+	case reflect.Map:
+		schema["minProperties"] = 1
+	default:
+		if isNumeric(t) {
+			schema["not"] = map[string]interface{}{"const": 0}
+		}
+	}
+	return nil
+}
+
+func schemaEq(schema map[string]interface{}, t reflect.Type, args ...string) error {
+	if err := wantArgs(args, 1); err != nil {
+		return err
+	}
+	v, err := schemaValue(t, args[0])
+	if err != nil {
+		return err
+	}
+	schema["const"] = v
+	return nil
+}
+
+func schemaNe(schema map[string]interface{}, t reflect.Type, args ...string) error {
+	if err := wantArgs(args, 1); err != nil {
+		return err
+	}
+	v, err := schemaValue(t, args[0])
+	if err != nil {
+		return err
+	}
+	schema["not"] = map[string]interface{}{"const": v}
+	return nil
+}
+
+func schemaEnum(schema map[string]interface{}, t reflect.Type, args ...string) error {
+	vals, err := schemaValues(t, args)
+	if err != nil {
+		return err
+	}
+	schema["enum"] = vals
+	return nil
+}
+
+func schemaBound(keyword string) SchemaFunc {
+	return func(schema map[string]interface{}, t reflect.Type, args ...string) error {
+		if err := wantArgs(args, 1); err != nil {
+			return err
+		}
+		if !isNumeric(t) {
+			// lexicographic bounds have no JSON Schema counterpart
+			return nil
+		}
+		v, err := schemaValue(t, args[0])
+		if err != nil {
+			return err
+		}
+		schema[keyword] = v
+		return nil
+	}
+}
+
+func schemaRange(schema map[string]interface{}, t reflect.Type, args ...string) error {
+	if err := wantArgs(args, 2); err != nil {
+		return err
+	}
+	if err := schemaBound("minimum")(schema, t, args[0]); err != nil {
+		return err
+	}
+	return schemaBound("maximum")(schema, t, args[1])
+}
+
+func schemaItems(arrayKeyword, mapKeyword string) SchemaFunc {
+	return func(schema map[string]interface{}, t reflect.Type, args ...string) error {
+		if err := wantArgs(args, 1); err != nil {
+			return err
+		}
+		n, err := strconv.Atoi(args[0])
+		if err != nil {
+			return err
+		}
+		if t.Kind() == reflect.Map {
+			schema[mapKeyword] = n
+		} else {
+			schema[arrayKeyword] = n
+		}
+		return nil
+	}
+}
+
+func schemaUnique(schema map[string]interface{}, t reflect.Type, args ...string) error {
+	if len(args) == 0 {
+		schema["uniqueItems"] = true
+	}
+	return nil
+}
+
+func schemaContains(schema map[string]interface{}, t reflect.Type, args ...string) error {
+	if err := wantArgs(args, 1); err != nil {
+		return err
+	}
+	if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+		return fmt.Errorf("unexpected collection type: %v", t)
+	}
+	v, err := schemaValue(t.Elem(), args[0])
+	if err != nil {
+		return err
+	}
+	schema["contains"] = map[string]interface{}{"const": v}
+	return nil
+}
+
+func schemaSubset(schema map[string]interface{}, t reflect.Type, args ...string) error {
+	if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+		return fmt.Errorf("unexpected collection type: %v", t)
+	}
+	vals, err := schemaValues(t.Elem(), args)
+	if err != nil {
+		return err
+	}
+	items, _ := schema["items"].(map[string]interface{})
+	if items == nil {
+		items = make(map[string]interface{})
+		schema["items"] = items
+	}
+	items["enum"] = vals
+	return nil
+}
@@ -0,0 +1,201 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testSchemaAuthor struct {
+	Email string `json:"email" validate:"nonempty, maxlen(255)"`
+}
+
+type testSchemaComment struct {
+	Text    string               `json:"text" validate:"nonempty"`
+	Replies []*testSchemaComment `json:"replies,omitempty" validate:"optional, maxitems(10)"`
+}
+
+type testSchemaMeta struct {
+	Version int `json:"version" validate:"gt(0)"`
+}
+
+type testSchemaMessage struct {
+	testSchemaMeta
+	Title    string               `json:"title" validate:"nonempty, maxlen(255)"`
+	Kind     string               `json:"kind" validate:"enum(text, audio, video)"`
+	Rating   float64              `json:"rating" validate:"optional, range(0, 5)"`
+	Priority uint8                `json:"priority" validate:"enum(1, 2, 3)"`
+	Author   *testSchemaAuthor    `json:"author" validate:"required"`
+	Tags     []string             `json:"tags" validate:"optional, unique, maxitems(8), subset(a, b, c)"`
+	Attrs    map[string]int       `json:"attrs" validate:"optional, maxitems(4)"`
+	Comments []*testSchemaComment `json:"comments" validate:"optional"`
+	Slug     string               `json:"slug" validate:"schema_slug"`
+	Ignored  string               `json:"-" validate:"nonempty"`
+	Skipped  string               `validate:"-"`
+	internal string
+}
+
+func TestJSONSchema(t *testing.T) {
+	Register("schema_slug", func(v string) bool {
+		return !strings.ContainsAny(v, " /")
+	})
+	RegisterSchema("schema_slug", func(schema map[string]interface{}, t reflect.Type, args ...string) error {
+		schema["pattern"] = "^[^ /]*$"
+		return nil
+	})
+
+	schema, err := JSONSchema(reflect.TypeOf(&testSchemaMessage{}))
+	assert.NoError(t, err)
+
+	got, err := json.Marshal(schema)
+	assert.NoError(t, err)
+
+	want := `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"properties": {
+			"version": {"type": "integer", "exclusiveMinimum": 0},
+			"title": {"type": "string", "minLength": 1, "maxLength": 255},
+			"kind": {"type": "string", "enum": ["text", "audio", "video"]},
+			"rating": {"type": "number", "minimum": 0, "maximum": 5},
+			"priority": {"type": "integer", "enum": [1, 2, 3]},
+			"author": {"$ref": "#/$defs/testSchemaAuthor"},
+			"tags": {"type": "array", "items": {"type": "string", "enum": ["a", "b", "c"]}, "uniqueItems": true, "maxItems": 8},
+			"attrs": {"type": "object", "additionalProperties": {"type": "integer"}, "maxProperties": 4},
+			"comments": {"type": "array", "items": {"$ref": "#/$defs/testSchemaComment"}},
+			"slug": {"type": "string", "pattern": "^[^ /]*$"},
+			"Skipped": {"type": "string"}
+		},
+		"required": ["author", "kind", "priority", "slug", "title", "version"],
+		"$defs": {
+			"testSchemaAuthor": {
+				"type": "object",
+				"properties": {
+					"email": {"type": "string", "minLength": 1, "maxLength": 255}
+				},
+				"required": ["email"]
+			},
+			"testSchemaComment": {
+				"type": "object",
+				"properties": {
+					"text": {"type": "string", "minLength": 1},
+					"replies": {"type": "array", "items": {"$ref": "#/$defs/testSchemaComment"}, "maxItems": 10}
+				},
+				"required": ["text"]
+			}
+		}
+	}`
+	assert.JSONEq(t, want, string(got))
+}
+
+func TestJSONSchemaDefs(t *testing.T) {
+	var unmapped []string
+	defs, err := JSONSchemaDefs([]reflect.Type{reflect.TypeOf(&testSchemaComment{})},
+		WithSchemaRefPrefix("#/definitions/"),
+		WithSchemaDocs(map[string]string{"testSchemaComment.Text": "The comment body."}),
+		WithSchemaUnmapped(func(schema map[string]interface{}, tag ValidateTag) {
+			unmapped = append(unmapped, tag.Op)
+		}),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"testSchemaComment": map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"text": map[string]interface{}{"type": "string", "minLength": 1, "description": "The comment body."},
+				"replies": map[string]interface{}{
+					"type":     "array",
+					"items":    map[string]interface{}{"$ref": "#/definitions/testSchemaComment"},
+					"maxItems": 10,
+				},
+			},
+			"required": []string{"text"},
+		},
+	}, defs)
+	assert.Equal(t, []string{"optional"}, unmapped)
+
+	_, err = JSONSchemaDefs([]reflect.Type{reflect.TypeOf(42)})
+	assert.EqualError(t, err, "Schema definitions are generated for named struct types, int given")
+}
+
+func TestJSONSchema_Errors(t *testing.T) {
+	type BadArg struct {
+		Title string `validate:"maxlen(foo)"`
+	}
+	type BadMap struct {
+		Attrs map[int]string
+	}
+	type BadAlias struct {
+		Title string `validate:"schema_missing_param"`
+	}
+	RegisterAlias("schema_missing_param", "maxlen($1)")
+
+	for _, v := range []interface{}{BadArg{}, BadMap{}, BadAlias{}} {
+		t.Run(fmt.Sprintf("%T", v), func(t *testing.T) {
+			_, err := JSONSchema(reflect.TypeOf(v))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestJSONSchema_Default(t *testing.T) {
+	type Query struct {
+		PageSize int    `json:"page_size" validate:"default(20), range(1, 100)"`
+		Email    string `json:"email" validate:"trim, lower, nonempty"`
+	}
+
+	schema, err := JSONSchema(reflect.TypeOf(Query{}))
+	assert.NoError(t, err)
+	props := schema["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "integer", "default": 20, "minimum": 1, "maximum": 100}, props["page_size"])
+	assert.Equal(t, map[string]interface{}{"type": "string", "minLength": 1}, props["email"])
+}
+
+type testSchemaIDMeta struct {
+	ID    string `json:"id"`
+	Memo  string `validate:"nonempty"`
+	Label string `json:"By" validate:"maxlen(8)"`
+}
+
+type testSchemaAudit struct {
+	Memo string
+	By   string `validate:"nonempty"`
+}
+
+type testSchemaShadowed struct {
+	testSchemaIDMeta
+	testSchemaAudit
+	ID int `json:"id" validate:"gt(0)"`
+}
+
+func TestJSONSchema_Shadowing(t *testing.T) {
+	schema, err := JSONSchema(reflect.TypeOf(testSchemaShadowed{}))
+	assert.NoError(t, err)
+
+	got, err := json.Marshal(schema)
+	assert.NoError(t, err)
+	// the outer id wins over the embedded one declared before it, the tagged
+	// By wins over the untagged one at the same depth and the ambiguous Memo
+	// is dropped, as in encoding/json
+	assert.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"properties": {
+			"id": {"type": "integer", "exclusiveMinimum": 0},
+			"By": {"type": "string", "maxLength": 8}
+		},
+		"required": ["By", "id"]
+	}`, string(got))
+
+	data, err := json.Marshal(testSchemaShadowed{
+		testSchemaIDMeta: testSchemaIDMeta{Memo: "n", Label: "l"},
+		testSchemaAudit:  testSchemaAudit{Memo: "m", By: "b"},
+		ID:               1,
+	})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"id": 1, "By": "l"}`, string(data))
+}
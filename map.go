@@ -0,0 +1,104 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ValidateMap validates a decoded JSON document against a set of rules. The
+// rule keys are dotted paths into the document, `[*]` addresses every element
+// of an array; the rule values use the tag grammar:
+//
+//	err := validator.ValidateMap(doc, map[string]string{
+//		"title":        "nonempty, maxlen(255)",
+//		"author.email": "nonempty",
+//		"tags":         "maxitems(10)",
+//		"tags[*]":      "nonempty",
+//	})
+//
+// A missing key is validated as a nil value.
+func ValidateMap(data map[string]interface{}, rules map[string]string, opts ...Option) error {
+	s := newValidation(context.Background(), opts...)
+	s.start(data)
+	return s.end(s.result(s.validateMap(data, rules)))
+}
+
+func (s *validation) validateMap(data map[string]interface{}, rules map[string]string) error {
+	keys := make([]string, 0, len(rules))
+	for key := range rules {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		rset, err := parseValueRules(rules[key])
+		if err != nil {
+			return err
+		}
+		segs, err := parseRuleKey(key)
+		if err != nil {
+			return err
+		}
+		var visit func(v interface{}, segs []string, name string, path fieldPath) error
+		visit = func(v interface{}, segs []string, name string, path fieldPath) error {
+			if err := s.ctx.Err(); err != nil {
+				return err
+			}
+			if len(segs) == 0 {
+				_, err := s.checkTags(s.ctx, name, path, "", rset, v)
+				return s.report(err)
+			}
+			seg := segs[0]
+			if seg == "[*]" {
+				if v == nil {
+					return nil
+				}
+				elems, ok := v.([]interface{})
+				if !ok {
+					return fmt.Errorf("Rule %q expects an array at %q, %T found", key, path.pointer, v)
+				}
+				for i, elem := range elems {
+					if err := visit(elem, segs[1:], name, path.index(i)); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+			var next interface{}
+			if v != nil {
+				obj, ok := v.(map[string]interface{})
+				if !ok {
+					return fmt.Errorf("Rule %q expects an object at %q, %T found", key, path.pointer, v)
+				}
+				next = obj[seg]
+			}
+			return visit(next, segs[1:], seg, path.field(seg, seg))
+		}
+		if err := visit(data, segs, "", fieldPath{}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func parseRuleKey(key string) ([]string, error) {
+	segs := []string{}
+	for _, part := range strings.Split(key, ".") {
+		n := 0
+		for strings.HasSuffix(part, "[*]") {
+			part = strings.TrimSuffix(part, "[*]")
+			n++
+		}
+		if part == "" || strings.ContainsAny(part, "[]") {
+			return nil, fmt.Errorf("Malformed rule key %q", key)
+		}
+		segs = append(segs, part)
+		for ; n > 0; n-- {
+			segs = append(segs, "[*]")
+		}
+	}
+	return segs, nil
+}
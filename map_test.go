@@ -0,0 +1,134 @@
+package validator
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateMap(t *testing.T) {
+	rules := map[string]string{
+		"title":          "nonempty, maxlen(8)",
+		"rating":         "optional, range(1, 5)",
+		"author.email":   "nonempty",
+		"tags":           "optional, maxitems(2)",
+		"tags[*]":        "nonempty",
+		"items[*].price": "gt(0)",
+	}
+
+	tests := []struct {
+		name        string
+		input       string
+		wantErr     string
+		wantPath    string
+		wantPointer string
+	}{
+		{
+			name:  "valid",
+			input: `{"title": "foo", "rating": 4.5, "author": {"email": "me@example.com"}, "tags": ["a", "b"], "items": [{"price": 1}]}`,
+		},
+		{
+			name:        "missing key",
+			input:       `{"author": {"email": "me@example.com"}}`,
+			wantErr:     `Validation failed for field "title": should not be empty`,
+			wantPath:    "title",
+			wantPointer: "/title",
+		},
+		{
+			name:        "float out of range",
+			input:       `{"title": "foo", "rating": 5.5, "author": {"email": "me@example.com"}}`,
+			wantErr:     `Validation failed for field "rating": should be in the range [1, 5]`,
+			wantPath:    "rating",
+			wantPointer: "/rating",
+		},
+		{
+			name:        "missing nested object",
+			input:       `{"title": "foo"}`,
+			wantErr:     `Validation failed for field "email": should not be empty`,
+			wantPath:    "author.email",
+			wantPointer: "/author/email",
+		},
+		{
+			name:        "array element",
+			input:       `{"title": "foo", "author": {"email": "me@example.com"}, "tags": ["a", ""]}`,
+			wantErr:     `Validation failed for field "tags": should not be empty`,
+			wantPath:    "tags[1]",
+			wantPointer: "/tags/1",
+		},
+		{
+			name:        "nested array element",
+			input:       `{"title": "foo", "author": {"email": "me@example.com"}, "items": [{"price": 1}, {"price": 0}]}`,
+			wantErr:     `Validation failed for field "price": should be greater than 0`,
+			wantPath:    "items[1].price",
+			wantPointer: "/items/1/price",
+		},
+		{
+			name:    "not an object",
+			input:   `{"title": "foo", "author": "me@example.com"}`,
+			wantErr: `Rule "author.email" expects an object at "/author", string found`,
+		},
+		{
+			name:    "not an array",
+			input:   `{"title": "foo", "author": {"email": "me@example.com"}, "items": {"price": 1}}`,
+			wantErr: `Rule "items[*].price" expects an array at "/items", map[string]interface {} found`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var data map[string]interface{}
+			assert.NoError(t, json.Unmarshal([]byte(tt.input), &data))
+			err := ValidateMap(data, rules)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Equal(t, tt.wantErr, err.Error())
+			if tt.wantPath != "" {
+				fe, ok := err.(*FieldError)
+				if assert.True(t, ok) {
+					assert.Equal(t, tt.wantPath, fe.Path)
+					assert.Equal(t, tt.wantPointer, fe.Pointer)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateMap_Normalizers(t *testing.T) {
+	data := map[string]interface{}{"title": "  "}
+	err := ValidateMap(data, map[string]string{"title": "trim, nonempty"})
+	assert.EqualError(t, err, `Normalizer "trim" can't be applied to a value passed by copy, normalize it before the validation`)
+	assert.Equal(t, "  ", data["title"])
+}
+
+func TestParseRuleKey(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    []string
+		wantErr bool
+	}{
+		{input: "title", want: []string{"title"}},
+		{input: "author.email", want: []string{"author", "email"}},
+		{input: "tags[*]", want: []string{"tags", "[*]"}},
+		{input: "matrix[*][*].x", want: []string{"matrix", "[*]", "[*]", "x"}},
+		{input: "", wantErr: true},
+		{input: "author..email", wantErr: true},
+		{input: "[*]", wantErr: true},
+		{input: "tags[0]", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			segs, err := parseRuleKey(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, segs)
+		})
+	}
+}
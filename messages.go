@@ -0,0 +1,152 @@
+package validator
+
+import (
+	"strings"
+	"text/template"
+)
+
+const (
+	DefaultLocale = "en"
+
+	// ValidationFailedKey is the message key of the wrapper around a field
+	// reason. Its MessageData.Reason holds the already translated reason.
+	ValidationFailedKey = "validation_failed"
+)
+
+type MessageData struct {
+	Field  string
+	Value  interface{}
+	Args   []interface{}
+	Reason string
+	// Index, Key and Other locate the offending element of a collection,
+	// see ElementError. Index and Other are -1 when they don't apply.
+	Index int
+	Key   interface{}
+	Other int
+}
+
+type Translator interface {
+	Translate(locale, key string, data MessageData) (string, bool)
+}
+
+type Catalog struct {
+	templates map[string]map[string]*template.Template
+}
+
+var DefaultCatalog = NewCatalog()
+
+var defaultMessages = map[string]string{
+	ValidationFailedKey: `Validation failed{{with .Field}} for field {{printf "%q" .}}{{end}}: {{.Reason}}`,
+
+	"contains": `should contain {{index .Args 0}}`,
+	"empty":    `should be empty`,
+	"enum":     `should be in range {{.Args}}`,
+	"eq":       `should be equal to {{index .Args 0}}`,
+	"eqfield":  `should be equal to {{with .Args}}field {{index . 0}}{{else}}the compared value{{end}}`,
+	"gt":       `should be greater than {{index .Args 0}}`,
+	"gte":      `should be greater or equal to {{index .Args 0}}`,
+	"gtefield": `should be greater or equal to {{with .Args}}field {{index . 0}}{{else}}the compared value{{end}}`,
+	"gtfield":  `should be greater than {{with .Args}}field {{index . 0}}{{else}}the compared value{{end}}`,
+	"len":      `length must be exactly {{index .Args 0}}`,
+	"lt":       `should be less than {{index .Args 0}}`,
+	"lte":      `should be less or equal to {{index .Args 0}}`,
+	"ltefield": `should be less or equal to {{with .Args}}field {{index . 0}}{{else}}the compared value{{end}}`,
+	"ltfield":  `should be less than {{with .Args}}field {{index . 0}}{{else}}the compared value{{end}}`,
+	"maxitems": `should contain at most {{index .Args 0}} items`,
+	"maxlen":   `length must be up to {{index .Args 0}}`,
+	"maxrunes": `length must be up to {{index .Args 0}} characters`,
+	"minitems": `should contain at least {{index .Args 0}} items`,
+	"minlen":   `length must be at least {{index .Args 0}}`,
+	"minrunes": `length must be at least {{index .Args 0}} characters`,
+	"ne":       `should not be equal to {{index .Args 0}}`,
+	"nefield":  `should not be equal to {{with .Args}}field {{index . 0}}{{else}}the compared value{{end}}`,
+	"nonempty": `should not be empty`,
+	"nonil":    `element {{if ge .Index 0}}at index {{.Index}}{{else}}with key {{.Key}}{{end}} should not be nil`,
+	"range":    `should be in the range [{{index .Args 0}}, {{index .Args 1}}]`,
+	"required": `is required`,
+	"sorted":   `should be sorted in {{with .Args}}{{index . 0}}{{else}}asc{{end}} order, element at index {{.Index}} is out of order`,
+	"subset":   `element at index {{.Index}} should be in range {{.Args}}`,
+	"unique":   `element at index {{.Index}} duplicates element at index {{.Other}}`,
+
+	TypeCheckHandle: `dynamic type {{printf "%T" .Value}} is not allowed`,
+}
+
+func init() {
+	for key, text := range defaultMessages {
+		if err := DefaultCatalog.Add(DefaultLocale, key, text); err != nil {
+			panic(err.Error())
+		}
+	}
+}
+
+func NewCatalog() *Catalog {
+	return &Catalog{
+		templates: make(map[string]map[string]*template.Template),
+	}
+}
+
+func (c *Catalog) Add(locale, key, text string) error {
+	tmpl, err := parseMessage(locale+"/"+key, text)
+	if err != nil {
+		return err
+	}
+	if _, ok := c.templates[locale]; !ok {
+		c.templates[locale] = make(map[string]*template.Template)
+	}
+	c.templates[locale][key] = tmpl
+	return nil
+}
+
+// Translate renders the template registered under key for locale, falling
+// back from a regional locale (e.g. "de-AT") to its base language ("de").
+func (c *Catalog) Translate(locale, key string, data MessageData) (string, bool) {
+	for locale != "" {
+		if tmpl, ok := c.templates[locale][key]; ok {
+			msg, err := execMessage(tmpl, data)
+			if err != nil {
+				return "", false
+			}
+			return msg, true
+		}
+		if ix := strings.LastIndexAny(locale, "-_"); ix > 0 {
+			locale = locale[:ix]
+		} else {
+			break
+		}
+	}
+	return "", false
+}
+
+func RegisterMessage(locale, handle, text string) error {
+	return DefaultCatalog.Add(locale, handle, text)
+}
+
+func translate(o *options, key string, data MessageData) (string, bool) {
+	if msg, ok := o.translator.Translate(o.locale, key, data); ok {
+		return msg, true
+	}
+	if o.locale != DefaultLocale {
+		return o.translator.Translate(DefaultLocale, key, data)
+	}
+	return "", false
+}
+
+func parseMessage(name, text string) (*template.Template, error) {
+	return template.New(name).Option("missingkey=zero").Parse(text)
+}
+
+func execMessage(tmpl *template.Template, data MessageData) (string, error) {
+	var res strings.Builder
+	if err := tmpl.Execute(&res, data); err != nil {
+		return "", err
+	}
+	return res.String(), nil
+}
+
+func renderMessage(text string, data MessageData) (string, error) {
+	tmpl, err := parseMessage(MessageTagName, text)
+	if err != nil {
+		return "", err
+	}
+	return execMessage(tmpl, data)
+}
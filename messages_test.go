@@ -0,0 +1,185 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranslatedMessages(t *testing.T) {
+	Register("messages_even", func(v int) (bool, string) {
+		return v%2 == 0, "should be even"
+	})
+	assert.NoError(t, RegisterMessage("de", "messages_even", `{{.Value}} ist ungerade`))
+
+	cat := NewCatalog()
+	assert.NoError(t, cat.Add("de", ValidationFailedKey, `Feld {{.Field}} ist ungültig: {{.Reason}}`))
+	assert.NoError(t, cat.Add("de", "range", `muss zwischen {{index .Args 0}} und {{index .Args 1}} liegen`))
+
+	type TestStruct struct {
+		Count int    `validate:"range(1, 10)"`
+		Even  int    `validate:"messages_even"`
+		Name  string `validate:"maxlen(3)"`
+	}
+
+	tests := []struct {
+		name    string
+		input   TestStruct
+		opts    []Option
+		wantErr string
+		wantMsg string
+	}{
+		{
+			name:    "default locale",
+			input:   TestStruct{Count: 42},
+			wantErr: `Validation failed for field "Count": should be in the range [1, 10]`,
+			wantMsg: "should be in the range [1, 10]",
+		},
+		{
+			name:    "custom catalog",
+			input:   TestStruct{Count: 42},
+			opts:    []Option{WithTranslator(cat), WithLocale("de")},
+			wantErr: `Feld Count ist ungültig: muss zwischen 1 und 10 liegen`,
+			wantMsg: "muss zwischen 1 und 10 liegen",
+		},
+		{
+			name:    "regional locale falls back to language",
+			input:   TestStruct{Count: 42},
+			opts:    []Option{WithTranslator(cat), WithLocale("de-AT")},
+			wantErr: `Feld Count ist ungültig: muss zwischen 1 und 10 liegen`,
+			wantMsg: "muss zwischen 1 und 10 liegen",
+		},
+		{
+			name:    "missing translation falls back to english",
+			input:   TestStruct{Count: 1, Name: "foobar"},
+			opts:    []Option{WithLocale("de")},
+			wantErr: `Validation failed for field "Name": length must be up to 3`,
+			wantMsg: "length must be up to 3",
+		},
+		{
+			name:    "custom validator message",
+			input:   TestStruct{Count: 1, Even: 3},
+			opts:    []Option{WithLocale("de")},
+			wantErr: `Validation failed for field "Even": 3 ist ungerade`,
+			wantMsg: "3 ist ungerade",
+		},
+		{
+			name:    "custom validator without message",
+			input:   TestStruct{Count: 1, Even: 3},
+			wantErr: `Validation failed for field "Even": should be even`,
+			wantMsg: "should be even",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.input, tt.opts...)
+			assert.Error(t, err)
+			assert.Equal(t, tt.wantErr, err.Error())
+			fe, ok := err.(*FieldError)
+			if assert.True(t, ok) {
+				assert.Equal(t, tt.wantMsg, fe.Message)
+			}
+		})
+	}
+}
+
+func TestCustomFieldMessage(t *testing.T) {
+	type TestStruct struct {
+		Title string `validate:"nonempty, maxlen(5)" validate_msg:"Please pick a {{.Field}} under {{index .Args 0}} characters, {{printf \"%q\" .Value}} is too long"`
+		Count int    `validate:"gt(0)"`
+	}
+
+	var err error
+
+	err = Validate(TestStruct{Title: "foo", Count: 1})
+	assert.NoError(t, err)
+
+	err = Validate(TestStruct{Title: "foobar", Count: 1})
+	assert.Error(t, err)
+	assert.Equal(t, `Validation failed for field "Title": Please pick a Title under 5 characters, "foobar" is too long`, err.Error())
+	fe, ok := err.(*FieldError)
+	if assert.True(t, ok) {
+		assert.Equal(t, "maxlen", fe.Handle)
+		assert.Equal(t, "length must be up to 5", fe.Reason)
+		assert.Equal(t, `Please pick a Title under 5 characters, "foobar" is too long`, fe.Message)
+	}
+
+	err = Validate(TestStruct{Title: "foo"})
+	assert.Error(t, err)
+	assert.Equal(t, `Validation failed for field "Count": should be greater than 0`, err.Error())
+
+	type MalformedStruct struct {
+		Title string `validate:"nonempty" validate_msg:"{{.Field"`
+	}
+	err = Validate(MalformedStruct{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `Malformed validate_msg tag for field "Title"`)
+}
+
+func TestDefaultMessages(t *testing.T) {
+	for handle := range stdHandles {
+		if handle == "none" || handle == "optional" {
+			// never fail
+			continue
+		}
+		_, ok := DefaultCatalog.templates[DefaultLocale][handle]
+		assert.True(t, ok, "no message for %q", handle)
+	}
+
+	type TestStruct struct {
+		Min      int
+		Unique   []int               `validate:"unique"`
+		Sorted   []int               `validate:"sorted(desc)"`
+		Asc      []int               `validate:"sorted"`
+		Subset   []string            `validate:"subset(a, b)"`
+		NoNil    []*int              `validate:"nonil"`
+		NoNilMap map[string]*int     `validate:"nonil"`
+		Field    int                 `validate:"gtfield(Min)"`
+		Contains []string            `validate:"contains(x)"`
+		Keyed    map[int]interface{} `validate:"nonil"`
+	}
+	one := 1
+	err := Validate(TestStruct{
+		Min:      5,
+		Unique:   []int{1, 2, 1},
+		Sorted:   []int{3, 1, 2},
+		Asc:      []int{1, 3, 2},
+		Subset:   []string{"a", "c"},
+		NoNil:    []*int{&one, nil},
+		NoNilMap: map[string]*int{"k": nil},
+		Field:    5,
+		Keyed:    map[int]interface{}{0: nil},
+	}, WithAllErrors())
+	var errs ValidationErrors
+	if assert.ErrorAs(t, err, &errs) {
+		msgs := make([]string, 0, len(errs))
+		for _, fe := range errs {
+			// the templates render the reasons of the validators
+			assert.Equal(t, fe.Reason, fe.Message)
+			msgs = append(msgs, fe.Message)
+		}
+		assert.Equal(t, []string{
+			"element at index 2 duplicates element at index 0",
+			"should be sorted in desc order, element at index 2 is out of order",
+			"should be sorted in asc order, element at index 2 is out of order",
+			"element at index 1 should be in range [a b]",
+			"element at index 1 should not be nil",
+			"element with key k should not be nil",
+			"should be greater than field Min",
+			"should contain x",
+			"element with key 0 should not be nil",
+		}, msgs)
+	}
+	assert.EqualError(t, VarWithValue(1, 2, "gtefield"), "Validation failed: should be greater or equal to the compared value")
+
+	cat := NewCatalog()
+	assert.NoError(t, cat.Add("de", "unique", `Element {{.Index}} wiederholt Element {{.Other}}`))
+	assert.NoError(t, cat.Add("de", "nonil", `Element {{if ge .Index 0}}{{.Index}}{{else}}{{printf "%q" .Key}}{{end}} fehlt`))
+	type Translated struct {
+		IDs  []int           `validate:"unique"`
+		Refs map[string]*int `validate:"nonil"`
+	}
+	err = Validate(Translated{IDs: []int{7, 8, 7}, Refs: map[string]*int{"a": nil}}, WithTranslator(cat), WithLocale("de"), WithAllErrors())
+	assert.EqualError(t, err, `Validation failed for field "IDs": Element 2 wiederholt Element 0; Validation failed for field "Refs": Element "a" fehlt`)
+}
@@ -0,0 +1,133 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// NormalizeFunc rewrites the settable value v in place. args are the
+// normalizer arguments from the tag.
+type NormalizeFunc func(v reflect.Value, args ...string) error
+
+var normalizers = make(map[string]NormalizeFunc)
+
+func init() {
+	RegisterNormalizer("collapse_ws", NormalizeCollapseWS)
+	RegisterNormalizer("default", NormalizeDefault)
+	RegisterNormalizer("lower", NormalizeLower)
+	RegisterNormalizer("trim", NormalizeTrim)
+	RegisterNormalizer("upper", NormalizeUpper)
+}
+
+// RegisterNormalizer registers a normalizer usable in the validate tags
+// alongside the validators. The normalizers run when Validate is given a
+// pointer, in a pass over the whole value that completes before any
+// validator is called.
+func RegisterNormalizer(handle string, fn NormalizeFunc) error {
+	if _, ok := validators[handle]; ok {
+		return duplicateValidatorDefErr(handle)
+	}
+	if _, ok := aliases[handle]; ok {
+		return duplicateValidatorDefErr(handle)
+	}
+	if _, ok := normalizers[handle]; ok {
+		return duplicateValidatorDefErr(handle)
+	}
+	normalizers[handle] = fn
+	return nil
+}
+
+// NormalizeDefault sets a zero value to the argument converted to the value
+// type; a nil pointer is set to point to it.
+func NormalizeDefault(v reflect.Value, args ...string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("default accepts 1 argument, %d given", len(args))
+	}
+	if !v.IsZero() {
+		return nil
+	}
+	t := v.Type()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	def, err := convStringVal(args[0], t.Kind())
+	if err != nil {
+		return err
+	}
+	if !def.Type().ConvertibleTo(t) {
+		return fmt.Errorf("%v is not assignable to %v", def.Type(), t)
+	}
+	def = def.Convert(t)
+	if v.Kind() == reflect.Ptr {
+		p := reflect.New(t)
+		p.Elem().Set(def)
+		def = p
+	}
+	v.Set(def)
+	return nil
+}
+
+func NormalizeTrim(v reflect.Value, args ...string) error {
+	return normalizeStrings(v, "trim", strings.TrimSpace)
+}
+
+func NormalizeLower(v reflect.Value, args ...string) error {
+	return normalizeStrings(v, "lower", strings.ToLower)
+}
+
+func NormalizeUpper(v reflect.Value, args ...string) error {
+	return normalizeStrings(v, "upper", strings.ToUpper)
+}
+
+// NormalizeCollapseWS replaces every whitespace run with a single space and
+// trims the string.
+func NormalizeCollapseWS(v reflect.Value, args ...string) error {
+	return normalizeStrings(v, "collapse_ws", func(s string) string {
+		return strings.Join(strings.Fields(s), " ")
+	})
+}
+
+// normalizeStrings applies fn to a string, a string pointer or the elements
+// of a string slice.
+func normalizeStrings(v reflect.Value, handle string, fn func(string) string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(fn(v.String()))
+		return nil
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		return normalizeStrings(v.Elem(), handle, fn)
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() != reflect.String {
+			break
+		}
+		for i := 0; i < v.Len(); i++ {
+			v.Index(i).SetString(fn(v.Index(i).String()))
+		}
+		return nil
+	}
+	return fmt.Errorf("%s applies to strings, %v given", handle, v.Type())
+}
+
+func (s *validation) normalizeField(name string, path fieldPath, v reflect.Value, rules *ruleSet) error {
+	var old interface{}
+	if s.audit {
+		old = snapshot(v)
+	}
+	for _, tag := range rules.normalize {
+		args := make([]string, 0, len(tag.Args))
+		for _, arg := range tag.Args {
+			args = append(args, fmt.Sprint(arg))
+		}
+		if err := normalizers[tag.Op](v, args...); err != nil {
+			return fmt.Errorf("Normalizer %q failed for field %q: %s", tag.Op, name, err)
+		}
+		if s.audit && !reflect.DeepEqual(old, snapshot(v)) {
+			old = s.record(name, path, tag.Op, old, v, rules.sensitive)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package validator
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	type Filter struct {
+		Tags []string `validate:"lower, unique"`
+	}
+	type Query struct {
+		Email    string  `validate:"trim, lower, nonempty"`
+		Name     string  `validate:"collapse_ws, maxlen(20)"`
+		Code     *string `validate:"upper"`
+		PageSize int     `validate:"default(20), range(1, 100)"`
+		Page     *int    `validate:"default(1)"`
+		Sort     string  `validate:"default(asc), enum(asc, desc)"`
+		Confirm  string  `validate:"trim, lower, eqfield(Email)"`
+		Filter   *Filter
+	}
+
+	code := "ab"
+	q := Query{
+		Email:   "  Bob@Example.COM ",
+		Name:    "  Bob   the\tBuilder ",
+		Code:    &code,
+		Confirm: "bob@example.com ",
+		Filter:  &Filter{Tags: []string{"Go", "go"}},
+	}
+	err := Validate(&q)
+	// normalization completes before the checks: unique sees the lowercased tags
+	assert.EqualError(t, err, `Validation failed for field "Tags": element at index 1 duplicates element at index 0`)
+
+	q.Filter.Tags = []string{"Go", "Rust"}
+	assert.NoError(t, Validate(&q))
+
+	page := 1
+	upper := "AB"
+	assert.Equal(t, Query{
+		Email:    "bob@example.com",
+		Name:     "Bob the Builder",
+		Code:     &upper,
+		PageSize: 20,
+		Page:     &page,
+		Sort:     "asc",
+		Confirm:  "bob@example.com",
+		Filter:   &Filter{Tags: []string{"go", "rust"}},
+	}, q)
+}
+
+type testNormalizeItem struct {
+	Name string `validate:"trim, nonempty"`
+}
+
+func TestNormalize_MapsAndInterfaces(t *testing.T) {
+	type Order struct {
+		Items map[string]testNormalizeItem
+		Any   interface{}
+		Refs  map[string]interface{}
+	}
+
+	o := Order{
+		Items: map[string]testNormalizeItem{"a": {Name: " x "}, "b": {Name: "  "}},
+		Any:   testNormalizeItem{Name: " y "},
+		Refs:  map[string]interface{}{"c": testNormalizeItem{Name: " z "}},
+	}
+	err := Validate(&o)
+	assert.EqualError(t, err, `Validation failed for field "Name": should not be empty`)
+	assert.Equal(t, "Items[b].Name", err.(*FieldError).Path)
+
+	o.Items["b"] = testNormalizeItem{Name: "w "}
+	assert.NoError(t, Validate(&o))
+	assert.Equal(t, Order{
+		Items: map[string]testNormalizeItem{"a": {Name: "x"}, "b": {Name: "w"}},
+		Any:   testNormalizeItem{Name: "y"},
+		Refs:  map[string]interface{}{"c": testNormalizeItem{Name: "z"}},
+	}, o)
+
+	items := map[string]testNormalizeItem{"a": {Name: "  "}}
+	assert.EqualError(t, Validate(&items), `Validation failed for field "Name": should not be empty`)
+	assert.Equal(t, "", items["a"].Name)
+
+	items = map[string]testNormalizeItem{"a": {Name: " v "}}
+	changes, err := Sanitize(&items)
+	assert.NoError(t, err)
+	if assert.Len(t, changes, 1) {
+		assert.Equal(t, "[a].Name", changes[0].Path)
+	}
+	assert.Equal(t, "v", items["a"].Name)
+}
+
+func TestNormalize_NotPointer(t *testing.T) {
+	type TestStruct struct {
+		Name string `validate:"trim, nonempty"`
+		Size int    `validate:"default(5), gt(0)"`
+	}
+
+	// a value passed by copy cannot be normalized, the checks see it as is
+	ts := TestStruct{Name: " "}
+	assert.EqualError(t, Validate(ts), `Validation failed for field "Size": should be greater than 0`)
+	assert.Equal(t, TestStruct{Name: " "}, ts)
+
+	assert.EqualError(t, Validate(&ts), `Validation failed for field "Name": should not be empty`)
+	assert.Equal(t, TestStruct{Name: "", Size: 5}, ts)
+}
+
+func TestNormalize_Errors(t *testing.T) {
+	type BadDefault struct {
+		Size int `validate:"default(many)"`
+	}
+	err := Validate(&BadDefault{})
+	assert.EqualError(t, err, `Normalizer "default" failed for field "Size": strconv.ParseInt: parsing "many": invalid syntax`)
+
+	type BadKind struct {
+		Size int `validate:"trim"`
+	}
+	err = Validate(&BadKind{})
+	assert.EqualError(t, err, `Normalizer "trim" failed for field "Size": trim applies to strings, int given`)
+}
+
+func TestRegisterNormalizer(t *testing.T) {
+	err := RegisterNormalizer("test_strip_dashes", func(v reflect.Value, args ...string) error {
+		v.SetString(strings.ReplaceAll(v.String(), "-", ""))
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Error(t, RegisterNormalizer("trim", NormalizeTrim))
+	assert.Error(t, Register("trim", StdNonEmpty))
+	assert.Error(t, RegisterNormalizer("nonempty", NormalizeTrim))
+
+	type TestStruct struct {
+		Phone string `validate:"test_strip_dashes, len(7)"`
+	}
+	ts := TestStruct{Phone: "555-12-34"}
+	assert.NoError(t, Validate(&ts))
+	assert.Equal(t, "5551234", ts.Phone)
+}
@@ -0,0 +1,57 @@
+package validator
+
+import (
+	"reflect"
+	"time"
+)
+
+// Observer is notified of the validation progress, e.g. to collect metrics
+// or to trace the validation. It must be safe for concurrent use when the
+// validations sharing it run concurrently.
+type Observer interface {
+	// OnValidateStart is called when the validation of a value of type t
+	// starts.
+	OnValidateStart(t reflect.Type)
+	// OnFieldCheck is called after every validator call.
+	OnFieldCheck(c FieldCheck)
+	// OnValidateEnd is called with the validation result and duration.
+	OnValidateEnd(t reflect.Type, d time.Duration, err error)
+}
+
+// FieldCheck is a validator call reported to the Observer.
+type FieldCheck struct {
+	// Type is the type of the validated value
+	Type reflect.Type
+	// Path is the field path, empty for a standalone value
+	Path   string
+	Handle string
+	// Duration is the time spent in the validator func alone, without
+	// rendering the error message
+	Duration time.Duration
+	// Err is the check failure, nil if the field passed
+	Err error
+}
+
+// WithObserver reports the validation progress to o. Without an observer
+// the validation is not timed.
+func WithObserver(o Observer) Option {
+	return func(opts *options) {
+		opts.observer = o
+	}
+}
+
+func (s *validation) start(datum interface{}) {
+	if s.observer == nil {
+		return
+	}
+	s.datumType = reflect.TypeOf(datum)
+	s.started = time.Now()
+	s.observer.OnValidateStart(s.datumType)
+}
+
+func (s *validation) end(err error) error {
+	if s.observer != nil {
+		s.observer.OnValidateEnd(s.datumType, time.Since(s.started), err)
+	}
+	return err
+}
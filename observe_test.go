@@ -0,0 +1,171 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testObserver struct {
+	events []string
+}
+
+func (o *testObserver) OnValidateStart(t reflect.Type) {
+	o.events = append(o.events, fmt.Sprintf("start %v", t))
+}
+
+func (o *testObserver) OnFieldCheck(c FieldCheck) {
+	outcome := "ok"
+	if c.Err != nil {
+		outcome = "fail"
+	}
+	o.events = append(o.events, fmt.Sprintf("check %v %s %s %s", c.Type, c.Path, c.Handle, outcome))
+}
+
+func (o *testObserver) OnValidateEnd(t reflect.Type, d time.Duration, err error) {
+	outcome := "ok"
+	if err != nil {
+		outcome = "fail"
+	}
+	o.events = append(o.events, fmt.Sprintf("end %v %s", t, outcome))
+}
+
+type testObserved struct {
+	Name  string   `validate:"nonempty, maxlen(3)"`
+	Count int      `validate:"optional, gt(0)"`
+	Tags  []string `validate:"maxitems(2)"`
+}
+
+func TestWithObserver(t *testing.T) {
+	tests := []struct {
+		name     string
+		validate func(o Option) error
+		want     []string
+	}{
+		{
+			name: "struct",
+			validate: func(o Option) error {
+				return Validate(testObserved{Name: "foo", Tags: []string{"a"}}, o)
+			},
+			want: []string{
+				"start validator.testObserved",
+				"check validator.testObserved Name nonempty ok",
+				"check validator.testObserved Name maxlen ok",
+				"check validator.testObserved Count optional ok",
+				"check validator.testObserved Tags maxitems ok",
+				"end validator.testObserved ok",
+			},
+		},
+		{
+			name: "failed struct",
+			validate: func(o Option) error {
+				return Validate(&testObserved{Name: "fooo"}, o)
+			},
+			want: []string{
+				"start *validator.testObserved",
+				"check *validator.testObserved Name nonempty ok",
+				"check *validator.testObserved Name maxlen fail",
+				"end *validator.testObserved fail",
+			},
+		},
+		{
+			name: "all errors",
+			validate: func(o Option) error {
+				return Validate(testObserved{Count: -1, Tags: []string{"a", "b", "c"}}, o, WithAllErrors())
+			},
+			want: []string{
+				"start validator.testObserved",
+				"check validator.testObserved Name nonempty fail",
+				"check validator.testObserved Count optional ok",
+				"check validator.testObserved Count gt fail",
+				"check validator.testObserved Tags maxitems fail",
+				"end validator.testObserved fail",
+			},
+		},
+		{
+			name: "var",
+			validate: func(o Option) error {
+				return Var(42, "range(1, 100)", o)
+			},
+			want: []string{
+				"start int",
+				"check int  range ok",
+				"end int ok",
+			},
+		},
+		{
+			name: "map",
+			validate: func(o Option) error {
+				return ValidateMap(map[string]interface{}{"name": ""}, map[string]string{"name": "nonempty"}, o)
+			},
+			want: []string{
+				"start map[string]interface {}",
+				"check map[string]interface {} name nonempty fail",
+				"end map[string]interface {} fail",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &testObserver{}
+			tt.validate(WithObserver(o))
+			assert.Equal(t, tt.want, o.events)
+		})
+	}
+}
+
+type timedObserver struct {
+	checks []FieldCheck
+	total  time.Duration
+	err    error
+}
+
+func (o *timedObserver) OnValidateStart(t reflect.Type) {}
+
+func (o *timedObserver) OnFieldCheck(c FieldCheck) {
+	o.checks = append(o.checks, c)
+}
+
+func (o *timedObserver) OnValidateEnd(t reflect.Type, d time.Duration, err error) {
+	o.total, o.err = d, err
+}
+
+func TestWithObserver_Durations(t *testing.T) {
+	Register("observe_slow", func(v interface{}) bool {
+		time.Sleep(time.Millisecond)
+		return false
+	})
+
+	o := &timedObserver{}
+	err := Var("foo", "observe_slow", WithObserver(o))
+	assert.Error(t, err)
+	assert.Len(t, o.checks, 1)
+	assert.GreaterOrEqual(t, o.checks[0].Duration, time.Millisecond)
+	assert.GreaterOrEqual(t, o.total, o.checks[0].Duration)
+	assert.Same(t, err, o.err)
+
+	var fe *FieldError
+	assert.True(t, errors.As(o.checks[0].Err, &fe))
+	assert.Equal(t, "observe_slow", fe.Handle)
+}
+
+type slowTranslator struct{}
+
+func (slowTranslator) Translate(locale, key string, data MessageData) (string, bool) {
+	time.Sleep(20 * time.Millisecond)
+	return "", false
+}
+
+func TestWithObserver_CheckDurationExcludesMessages(t *testing.T) {
+	o := &timedObserver{}
+	err := Var("", "nonempty", WithObserver(o), WithTranslator(slowTranslator{}))
+	assert.Error(t, err)
+	assert.Len(t, o.checks, 1)
+	assert.Less(t, o.checks[0].Duration, 20*time.Millisecond)
+	assert.GreaterOrEqual(t, o.total, 20*time.Millisecond)
+}
@@ -0,0 +1,216 @@
+// Package openapi describes the validated types as OpenAPI 3.1 components.
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/osdrv/validator"
+	"gopkg.in/yaml.v3"
+)
+
+const Extension = "x-validate"
+
+// Components is the OpenAPI 3.1 components object describing a set of
+// validated types.
+type Components struct {
+	Schemas map[string]interface{} `json:"schemas" yaml:"schemas"`
+}
+
+type Option func(*options)
+
+type options struct {
+	docs map[string]string
+}
+
+// WithDocs sets the descriptions of the types and fields keyed by "Type" and
+// "Type.Field", qualified with the package path as returned by ParseDocs or
+// bare, see validator.WithSchemaDocs.
+func WithDocs(docs map[string]string) Option {
+	return func(o *options) {
+		o.docs = docs
+	}
+}
+
+// NewComponents builds the components.schemas of an OpenAPI 3.1 document for
+// the given struct types. The schemas follow validator.JSONSchema; validators
+// without a JSON Schema counterpart are listed in the x-validate extension
+// of the property.
+func NewComponents(types []reflect.Type, opts ...Option) (*Components, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	schemas, err := validator.JSONSchemaDefs(types,
+		validator.WithSchemaRefPrefix("#/components/schemas/"),
+		validator.WithSchemaDocs(o.docs),
+		validator.WithSchemaUnmapped(extension),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &Components{Schemas: schemas}, nil
+}
+
+// JSON returns the components as an OpenAPI document fragment.
+func (c *Components) JSON() ([]byte, error) {
+	return json.MarshalIndent(map[string]interface{}{"components": c}, "", "  ")
+}
+
+// YAML returns the components as an OpenAPI document fragment.
+func (c *Components) YAML() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(map[string]interface{}{"components": c}); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func extension(schema map[string]interface{}, tag validator.ValidateTag) {
+	switch tag.Op {
+	case "optional", "required", "none":
+		// reflected by the required list or a no-op
+		return
+	}
+	rule := tag.Op
+	if len(tag.Args) > 0 {
+		args := make([]string, 0, len(tag.Args))
+		for _, arg := range tag.Args {
+			args = append(args, fmt.Sprint(arg))
+		}
+		rule += "(" + strings.Join(args, ", ") + ")"
+	}
+	rules, _ := schema[Extension].([]string)
+	schema[Extension] = append(rules, rule)
+}
+
+// ParseDocs reads the doc comments of the struct types declared in the Go
+// files of dir. The result is keyed by "Type" and "Type.Field" qualified
+// with the import path of the package, resolved from the enclosing go.mod,
+// e.g. "example.com/api.User.Email". A field without a doc comment falls
+// back to its line comment.
+func ParseDocs(dir string) (map[string]string, error) {
+	fset := token.NewFileSet()
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	pkgPath, err := importPath(dir)
+	if err != nil {
+		return nil, err
+	}
+	docs := make(map[string]string)
+	for _, name := range files {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		src, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		file, err := parser.ParseFile(fset, name, src, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		prefix := pkgPath + "."
+		if file.Name.Name == "main" {
+			// reflect reports the path of a main package as main
+			prefix = "main."
+		}
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				doc := ts.Doc
+				if doc == nil && len(gen.Specs) == 1 {
+					doc = gen.Doc
+				}
+				addDoc(docs, prefix+ts.Name.Name, doc)
+				st, ok := ts.Type.(*ast.StructType)
+				if !ok {
+					continue
+				}
+				for _, field := range st.Fields.List {
+					doc := field.Doc
+					if doc == nil {
+						doc = field.Comment
+					}
+					for _, fname := range field.Names {
+						addDoc(docs, prefix+ts.Name.Name+"."+fname.Name, doc)
+					}
+				}
+			}
+		}
+	}
+	return docs, nil
+}
+
+// importPath resolves the import path of the package in dir from the
+// module it belongs to.
+func importPath(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for root := abs; ; {
+		mod, err := os.ReadFile(filepath.Join(root, "go.mod"))
+		if err == nil {
+			path := modulePath(mod)
+			if path == "" {
+				return "", fmt.Errorf("No module path in %s", filepath.Join(root, "go.mod"))
+			}
+			rel, err := filepath.Rel(root, abs)
+			if err != nil {
+				return "", err
+			}
+			if rel == "." {
+				return path, nil
+			}
+			return path + "/" + filepath.ToSlash(rel), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+		parent := filepath.Dir(root)
+		if parent == root {
+			return "", fmt.Errorf("No go.mod found for %s", dir)
+		}
+		root = parent
+	}
+}
+
+// modulePath returns the path of the module directive of a go.mod file.
+func modulePath(mod []byte) string {
+	for _, line := range strings.Split(string(mod), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], "\"`")
+		}
+	}
+	return ""
+}
+
+func addDoc(docs map[string]string, key string, doc *ast.CommentGroup) {
+	if doc == nil {
+		return
+	}
+	if text := strings.TrimSpace(doc.Text()); text != "" {
+		docs[key] = text
+	}
+}
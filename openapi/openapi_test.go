@@ -0,0 +1,120 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testAPIAddress struct {
+	City string `json:"city" validate:"nonempty"`
+}
+
+type testAPIUser struct {
+	Email    string          `json:"email" validate:"nonempty, maxlen(255)"`
+	Password string          `json:"password" validate:"nonempty"`
+	Confirm  string          `json:"confirm" validate:"eqfield(Password)"`
+	Age      int             `json:"age" validate:"optional, gte(18)"`
+	Address  *testAPIAddress `json:"address" validate:"required"`
+}
+
+func TestNewComponents(t *testing.T) {
+	comps, err := NewComponents(
+		[]reflect.Type{reflect.TypeOf(testAPIUser{})},
+		WithDocs(map[string]string{
+			"testAPIUser":       "A registered account.",
+			"testAPIUser.Email": "Primary contact address.",
+		}),
+	)
+	assert.NoError(t, err)
+
+	got, err := comps.YAML()
+	assert.NoError(t, err)
+
+	want := `components:
+  schemas:
+    testAPIAddress:
+      properties:
+        city:
+          minLength: 1
+          type: string
+      required:
+        - city
+      type: object
+    testAPIUser:
+      description: A registered account.
+      properties:
+        address:
+          $ref: '#/components/schemas/testAPIAddress'
+        age:
+          minimum: 18
+          type: integer
+        confirm:
+          type: string
+          x-validate:
+            - eqfield(Password)
+        email:
+          description: Primary contact address.
+          maxLength: 255
+          minLength: 1
+          type: string
+        password:
+          minLength: 1
+          type: string
+      required:
+        - address
+        - confirm
+        - email
+        - password
+      type: object
+`
+	assert.Equal(t, want, string(got))
+
+	_, err = comps.JSON()
+	assert.NoError(t, err)
+}
+
+func TestNewComponents_NotStruct(t *testing.T) {
+	_, err := NewComponents([]reflect.Type{reflect.TypeOf(42)})
+	assert.Error(t, err)
+}
+
+func TestParseDocs(t *testing.T) {
+	docs, err := ParseDocs("testdata")
+	assert.NoError(t, err)
+	const pkg = "github.com/osdrv/validator/openapi/testdata."
+	assert.Equal(t, map[string]string{
+		pkg + "User":       "User is a registered account.",
+		pkg + "User.Email": "Email is the primary contact address.",
+		pkg + "User.Name":  "Name is displayed publicly.",
+		pkg + "Role":       "Role grants permissions.",
+	}, docs)
+}
+
+// Cookie shares its name with http.Cookie.
+type Cookie struct {
+	Name string `json:"name"`
+}
+
+func TestNewComponents_QualifiedDocs(t *testing.T) {
+	types := []reflect.Type{reflect.TypeOf(Cookie{}), reflect.TypeOf(http.Cookie{})}
+
+	comps, err := NewComponents(types, WithDocs(map[string]string{
+		"github.com/osdrv/validator/openapi.Cookie":      "A local cookie.",
+		"github.com/osdrv/validator/openapi.Cookie.Name": "The local name.",
+		"net/http.Cookie":      "An HTTP cookie.",
+		"net/http.Cookie.Name": "The HTTP name.",
+	}))
+	assert.NoError(t, err)
+	local := comps.Schemas["Cookie"].(map[string]interface{})
+	assert.Equal(t, "A local cookie.", local["description"])
+	assert.Equal(t, "The local name.", local["properties"].(map[string]interface{})["name"].(map[string]interface{})["description"])
+	remote := comps.Schemas["net.http.Cookie"].(map[string]interface{})
+	assert.Equal(t, "An HTTP cookie.", remote["description"])
+	assert.Equal(t, "The HTTP name.", remote["properties"].(map[string]interface{})["Name"].(map[string]interface{})["description"])
+
+	_, err = NewComponents(types, WithDocs(map[string]string{"Cookie.Name": "Which one?"}))
+	assert.EqualError(t, err, `Doc key "Cookie.Name" is ambiguous: 2 types are named Cookie, qualify it with the package path`)
+}
@@ -0,0 +1,17 @@
+package docs
+
+// User is a registered account.
+type User struct {
+	// Email is the primary contact address.
+	Email string
+	Name  string // Name is displayed publicly.
+	Age   int
+}
+
+type (
+	// Role grants permissions.
+	Role struct {
+		Title string
+	}
+	Plain struct{}
+)
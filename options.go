@@ -0,0 +1,97 @@
+package validator
+
+import "reflect"
+
+type Option func(*options)
+
+type options struct {
+	locale     string
+	translator Translator
+	mask       *fieldMask
+	groups     []string
+	allowed    map[reflect.Type][]reflect.Type
+	maxDepth   int
+	// embeddedNames keeps the embedded struct names in the field paths
+	embeddedNames bool
+	allErrors     bool
+	observer      Observer
+}
+
+func WithLocale(locale string) Option {
+	return func(o *options) {
+		o.locale = locale
+	}
+}
+
+func WithTranslator(t Translator) Option {
+	return func(o *options) {
+		o.translator = t
+	}
+}
+
+// WithFields restricts the validation to the given dotted field paths and
+// everything nested in them. Path segments are matched against the Go, json
+// and protobuf field names.
+func WithFields(paths ...string) Option {
+	return func(o *options) {
+		o.mask = newFieldMask(paths, false)
+	}
+}
+
+// WithoutFields excludes the given dotted field paths from the validation.
+func WithoutFields(paths ...string) Option {
+	return func(o *options) {
+		o.mask = newFieldMask(paths, true)
+	}
+}
+
+// WithAllowedTypes restricts the dynamic types of the values held by fields of
+// the interface type iface, which is given as a nil pointer to the interface:
+//
+//	validator.WithAllowedTypes((*EventPayload)(nil), &Created{}, &Deleted{})
+func WithAllowedTypes(iface interface{}, types ...interface{}) Option {
+	return func(o *options) {
+		if o.allowed == nil {
+			o.allowed = make(map[reflect.Type][]reflect.Type)
+		}
+		it := reflect.TypeOf(iface).Elem()
+		for _, t := range types {
+			o.allowed[it] = append(o.allowed[it], reflect.TypeOf(t))
+		}
+	}
+}
+
+// WithEmbeddedNames reports the fields of embedded structs with qualified
+// paths like Meta.CreatedAt instead of the promoted CreatedAt.
+func WithEmbeddedNames() Option {
+	return func(o *options) {
+		o.embeddedNames = true
+	}
+}
+
+// WithMaxDepth limits the struct nesting depth, the validated struct itself
+// being at depth 1. A deeper struct fails the validation with a *DepthError.
+func WithMaxDepth(depth int) Option {
+	return func(o *options) {
+		o.maxDepth = depth
+	}
+}
+
+// WithAllErrors keeps validating after a field fails and returns every
+// failed field in ValidationErrors. A failed field is not descended into.
+func WithAllErrors() Option {
+	return func(o *options) {
+		o.allErrors = true
+	}
+}
+
+func newOptions(opts ...Option) *options {
+	o := &options{
+		locale:     DefaultLocale,
+		translator: DefaultCatalog,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
@@ -9,6 +9,68 @@ import (
 type ValidateTag struct {
 	Op   string
 	Args []interface{}
+	// Alias is the name of the alias the tag was expanded from, if any.
+	Alias string
+}
+
+const (
+	SkipDirective      = "-"
+	NoDiveDirective    = "nodive"
+	SensitiveDirective = "sensitive"
+)
+
+// ruleSet is a parsed validator chain with the directives taken out.
+type ruleSet struct {
+	tags []ValidateTag
+	// chains holds the tags of each validation group when several groups
+	// apply, tags holds them all then
+	chains    [][]ValidateTag
+	normalize []ValidateTag
+	skip      bool
+	nodive    bool
+	sensitive bool
+}
+
+func parseRules(def string) (*ruleSet, error) {
+	rules := &ruleSet{}
+	if strings.TrimSpace(def) == SkipDirective {
+		rules.skip = true
+		return rules, nil
+	}
+	tags, err := expandAliases(parseValidateTags(def))
+	if err != nil {
+		return nil, err
+	}
+	rules.tags = make([]ValidateTag, 0, len(tags))
+	for _, tag := range tags {
+		if tag.Op == NoDiveDirective {
+			rules.nodive = true
+			continue
+		}
+		if tag.Op == SensitiveDirective {
+			rules.sensitive = true
+			continue
+		}
+		if _, ok := normalizers[tag.Op]; ok {
+			rules.normalize = append(rules.normalize, tag)
+			continue
+		}
+		rules.tags = append(rules.tags, tag)
+	}
+	return rules, nil
+}
+
+// parseValueRules parses the rules of a value that can't be modified in
+// place, the normalizers are rejected instead of being dropped silently.
+func parseValueRules(def string) (*ruleSet, error) {
+	rules, err := parseRules(def)
+	if err != nil {
+		return nil, err
+	}
+	if len(rules.normalize) > 0 {
+		return nil, fmt.Errorf("Normalizer %q can't be applied to a value passed by copy, normalize it before the validation", rules.normalize[0].Op)
+	}
+	return rules, nil
 }
 
 type LookaheadReader struct {
@@ -134,7 +196,7 @@ func isNumericSep(ch rune) bool {
 }
 
 func isUtilChar(ch rune) bool {
-	return ch == '_'
+	return ch == '_' || ch == '$'
 }
 
 func readLiteral(r *LookaheadReader) string {
@@ -68,3 +68,17 @@ func TestParseValidateTags(t *testing.T) {
 		})
 	}
 }
+
+func TestParseValidateTags_Params(t *testing.T) {
+	tags := parseValidateTags("nonempty, range($1, $2)")
+	assert.Equal(t, []ValidateTag{
+		{
+			Op:   "nonempty",
+			Args: []interface{}{},
+		},
+		{
+			Op:   "range",
+			Args: []interface{}{"$1", "$2"},
+		},
+	}, tags)
+}
@@ -0,0 +1,91 @@
+package validator
+
+import "strings"
+
+// ValidatePartial validates only the fields selected by the dotted paths,
+// e.g. the paths of a PATCH update mask:
+//
+//	err := validator.ValidatePartial(msg, "Title", "owner.email")
+//
+// A selected struct field is validated along with everything nested in it.
+// Collection elements are addressed by the collection field path, so
+// "Items.Title" selects the title of every item.
+func ValidatePartial(datum interface{}, paths ...string) error {
+	return Validate(datum, WithFields(paths...))
+}
+
+// ValidateExcept validates all the fields except the ones matched by the
+// dotted paths and everything nested in them.
+func ValidateExcept(datum interface{}, paths ...string) error {
+	return Validate(datum, WithoutFields(paths...))
+}
+
+type fieldMask struct {
+	paths   [][]string
+	exclude bool
+}
+
+func newFieldMask(paths []string, exclude bool) *fieldMask {
+	m := &fieldMask{
+		paths:   make([][]string, 0, len(paths)),
+		exclude: exclude,
+	}
+	for _, path := range paths {
+		m.paths = append(m.paths, strings.Split(path, "."))
+	}
+	return m
+}
+
+// selectField reports whether the field tags should be checked and whether
+// the field contents should be visited. A field reachable by several paths
+// (e.g. a promoted field of an embedded struct) is selected if any of them is
+// selected and excluded if all of them are excluded.
+func (s *validation) selectField(paths ...fieldPath) (bool, bool) {
+	if s.mask == nil {
+		return true, true
+	}
+	var covered, partial bool
+	excluded := true
+	for _, path := range paths {
+		c, p := s.mask.match(path)
+		covered, partial = covered || c, partial || p
+		excluded = excluded && c
+	}
+	if s.mask.exclude {
+		return !excluded, !excluded
+	}
+	return covered, covered || partial
+}
+
+// match reports whether the path is covered by any of the mask paths and
+// whether it is a prefix of any of them.
+func (m *fieldMask) match(path fieldPath) (bool, bool) {
+	var covered, partial bool
+	for _, mpath := range m.paths {
+		n := matchPrefix(mpath, path.segs)
+		if n == len(mpath) {
+			covered = true
+		} else if n == len(path.segs) {
+			partial = true
+		}
+	}
+	return covered, partial
+}
+
+func matchPrefix(mpath []string, segs [][]string) int {
+	n := 0
+	for n < len(mpath) && n < len(segs) {
+		found := false
+		for _, name := range segs[n] {
+			if name == mpath[n] {
+				found = true
+				break
+			}
+		}
+		if !found {
+			break
+		}
+		n++
+	}
+	return n
+}
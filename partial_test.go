@@ -0,0 +1,76 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatePartial(t *testing.T) {
+	type Owner struct {
+		Email string `json:"email" validate:"nonempty"`
+		Name  string `json:"name" validate:"nonempty"`
+	}
+	type Item struct {
+		Title string `json:"title" validate:"nonempty"`
+		Price int    `json:"price" validate:"gt(0)"`
+	}
+	type Message struct {
+		Id       string  `json:"id" validate:"nonempty"`
+		Title    string  `json:"title" validate:"nonempty, maxlen(8)"`
+		Owner    *Owner  `json:"owner" validate:"nonempty"`
+		Items    []Item  `json:"items"`
+		Revision int64   `protobuf:"varint,5,opt,name=revision_id,json=revisionId,proto3" json:"revisionId,omitempty" validate:"gt(0)"`
+		Children []*Item `json:"children" validate:"maxitems(1)"`
+	}
+
+	msg := Message{
+		Title:    "foobarbaz",
+		Owner:    &Owner{Email: "me@example.com"},
+		Items:    []Item{{Title: "foo"}},
+		Children: []*Item{{Title: "foo", Price: 1}, {Title: "bar"}},
+	}
+
+	tests := []struct {
+		name     string
+		paths    []string
+		except   bool
+		wantPath string
+	}{
+		{name: "nothing selected", paths: []string{}},
+		{name: "top level field", paths: []string{"Title"}, wantPath: "Title"},
+		{name: "json name", paths: []string{"title"}, wantPath: "Title"},
+		{name: "nested valid field", paths: []string{"owner.email"}},
+		{name: "nested invalid field", paths: []string{"owner.email", "Owner.Name"}, wantPath: "Owner.Name"},
+		{name: "whole nested struct", paths: []string{"Owner"}, wantPath: "Owner.Name"},
+		{name: "collection element field", paths: []string{"items.title"}},
+		{name: "collection element invalid field", paths: []string{"Items.Price"}, wantPath: "Items[0].Price"},
+		{name: "protobuf name", paths: []string{"revision_id"}, wantPath: "Revision"},
+		{name: "field mask", paths: []string{"id", "title"}, wantPath: "Id"},
+		{name: "partial collection does not check the collection tags", paths: []string{"children.title"}},
+		{name: "pointer collection element field", paths: []string{"children.price"}, wantPath: "Children[1].Price"},
+		{name: "collection tags", paths: []string{"children"}, wantPath: "Children"},
+		{name: "exclude", paths: []string{"Id", "Title", "Owner", "Items", "Revision"}, except: true, wantPath: "Children"},
+		{name: "exclude nested", paths: []string{"Id", "Title", "Owner.Name", "Items", "Children"}, except: true, wantPath: "Revision"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			if tt.except {
+				err = ValidateExcept(msg, tt.paths...)
+			} else {
+				err = ValidatePartial(msg, tt.paths...)
+			}
+			if tt.wantPath == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			fe, ok := err.(*FieldError)
+			if assert.True(t, ok) {
+				assert.Equal(t, tt.wantPath, fe.Path)
+			}
+		})
+	}
+}
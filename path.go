@@ -0,0 +1,82 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+type fieldPath struct {
+	path    string
+	pointer string
+	// segs holds the names a field can be referred to by (Go, json and
+	// protobuf names) for every struct field on the path. Collection
+	// indices are not included.
+	segs [][]string
+}
+
+func (p fieldPath) field(name, jsonName string, alts ...string) fieldPath {
+	path := name
+	if p.path != "" {
+		path = p.path + "." + name
+	}
+	segs := make([][]string, len(p.segs), len(p.segs)+1)
+	copy(segs, p.segs)
+	return fieldPath{
+		path:    path,
+		pointer: p.pointer + "/" + escapePointer(jsonName),
+		segs:    append(segs, append([]string{name, jsonName}, alts...)),
+	}
+}
+
+func (p fieldPath) index(ix interface{}) fieldPath {
+	return fieldPath{
+		path:    fmt.Sprintf("%s[%v]", p.path, ix),
+		pointer: p.pointer + "/" + escapePointer(fmt.Sprint(ix)),
+		segs:    p.segs,
+	}
+}
+
+func (p fieldPath) dynamic(t reflect.Type) fieldPath {
+	return fieldPath{
+		path:    fmt.Sprintf("%s.(%v)", p.path, t),
+		pointer: p.pointer,
+		segs:    p.segs,
+	}
+}
+
+func (p fieldPath) name() string {
+	if len(p.segs) == 0 {
+		return ""
+	}
+	return p.segs[len(p.segs)-1][0]
+}
+
+func (p fieldPath) structField(field reflect.StructField) fieldPath {
+	if name := protoName(field); name != "" {
+		return p.field(field.Name, jsonName(field), name)
+	}
+	return p.field(field.Name, jsonName(field))
+}
+
+func escapePointer(s string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(s)
+}
+
+func jsonName(field reflect.StructField) string {
+	if tag, ok := field.Tag.Lookup("json"); ok {
+		if name := strings.Split(tag, ",")[0]; name != "" && name != "-" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+func protoName(field reflect.StructField) string {
+	for _, opt := range strings.Split(field.Tag.Get("protobuf"), ",") {
+		if strings.HasPrefix(opt, "name=") {
+			return strings.TrimPrefix(opt, "name=")
+		}
+	}
+	return ""
+}
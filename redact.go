@@ -0,0 +1,44 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Redacted replaces the value of a sensitive field in the errors.
+const Redacted = "[REDACTED]"
+
+// sensitiveReason returns the reason of a failed sensitive field. The std
+// reasons never include the value; the reason of any other validator might,
+// so it is replaced as a whole: looking the value up in the text would both
+// garble the reason and hint at the value.
+func sensitiveReason(handle string, err error) string {
+	if stdHandles[handle] {
+		return err.Error()
+	}
+	var usage *UsageError
+	if errors.As(err, &usage) {
+		return "cannot be validated"
+	}
+	return "constraint mismatch"
+}
+
+// redactedError hides the text of an error mentioning a sensitive value and
+// keeps it available to errors.Is and errors.As.
+type redactedError struct {
+	err  error
+	text string
+}
+
+func (e *redactedError) Error() string {
+	return e.text
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+// Format keeps %#v from printing the wrapped error.
+func (e *redactedError) Format(f fmt.State, verb rune) {
+	fmt.Fprint(f, e.text)
+}
@@ -0,0 +1,163 @@
+package validator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testSecretValue = "hunter2-s3cr3t"
+
+var errTestCommon = errors.New("password is too common")
+
+func init() {
+	Register("test_not_echoed", func(v string) (bool, string) {
+		return false, fmt.Sprintf("%q is not accepted", v)
+	})
+	Register("test_not_common", func(v string) error {
+		return fmt.Errorf("%s: %w", v, errTestCommon)
+	})
+}
+
+// assertNoSecret checks every printable form of err for the secret.
+func assertNoSecret(t *testing.T, err error) {
+	t.Helper()
+	assert.Error(t, err)
+	out, jerr := json.Marshal(err)
+	assert.NoError(t, jerr)
+	for _, s := range []string{
+		err.Error(),
+		fmt.Sprintf("%v", err),
+		fmt.Sprintf("%+v", err),
+		fmt.Sprintf("%#v", err),
+		string(out),
+	} {
+		assert.NotContains(t, s, testSecretValue)
+	}
+	var fe *FieldError
+	if errors.As(err, &fe) {
+		assert.Equal(t, Redacted, fe.Value)
+		assert.NotContains(t, fe.Reason, testSecretValue)
+		assert.NotContains(t, fe.Message, testSecretValue)
+		assert.NotContains(t, fmt.Sprintf("%#v", fe.Err), testSecretValue)
+		assert.NotContains(t, fe.Err.Error(), testSecretValue)
+	}
+}
+
+func TestSensitive(t *testing.T) {
+	type Password struct {
+		Password string `validate:"sensitive, maxlen(8)"`
+	}
+	type Token struct {
+		Token *string `validate:"sensitive, eq(abc)"`
+	}
+	type Pin struct {
+		Pin string `validate:"sensitive, test_not_echoed"`
+	}
+	type Hint struct {
+		Hint string `validate:"sensitive, test_not_echoed" validate_msg:"{{.Value}} is not a hint"`
+	}
+
+	type ShortPin struct {
+		Pin string `validate:"sensitive, len(4)"`
+	}
+	type Code struct {
+		Code int `validate:"sensitive, range(1, 100)"`
+	}
+
+	secret := testSecretValue
+	tests := []struct {
+		name    string
+		input   interface{}
+		wantErr string
+	}{
+		{
+			name:    "std reason",
+			input:   Password{Password: testSecretValue},
+			wantErr: `Validation failed for field "Password": length must be up to 8`,
+		},
+		{
+			name:    "pointer value",
+			input:   Token{Token: &secret},
+			wantErr: `Validation failed for field "Token": unsupported kind: ptr`,
+		},
+		{
+			name:    "custom reason",
+			input:   Pin{Pin: testSecretValue},
+			wantErr: `Validation failed for field "Pin": constraint mismatch`,
+		},
+		{
+			name:    "value in the std reason",
+			input:   ShortPin{Pin: "4"},
+			wantErr: `Validation failed for field "Pin": length must be exactly 4`,
+		},
+		{
+			name:    "value in the args",
+			input:   Code{Code: 0},
+			wantErr: `Validation failed for field "Code": should be in the range [1, 100]`,
+		},
+		{
+			name:    "custom message",
+			input:   Hint{Hint: testSecretValue},
+			wantErr: `Validation failed for field "Hint": [REDACTED] is not a hint`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.input)
+			assertNoSecret(t, err)
+			assert.EqualError(t, err, tt.wantErr)
+
+			err = Validate(tt.input, WithAllErrors())
+			assertNoSecret(t, err)
+		})
+	}
+}
+
+func TestSensitive_ErrorChain(t *testing.T) {
+	type Account struct {
+		Password string `validate:"sensitive, test_not_common"`
+	}
+	err := Validate(Account{Password: testSecretValue})
+	assertNoSecret(t, err)
+	assert.EqualError(t, err, `Validation failed for field "Password": constraint mismatch`)
+	assert.True(t, errors.Is(err, errTestCommon))
+}
+
+func TestSensitive_Var(t *testing.T) {
+	err := Var(testSecretValue, "sensitive, enum(a, b)")
+	assertNoSecret(t, err)
+
+	err = ValidateMap(map[string]interface{}{"token": testSecretValue}, map[string]string{"token": "sensitive, test_not_echoed"})
+	assertNoSecret(t, err)
+}
+
+func TestSensitive_Sanitize(t *testing.T) {
+	type Account struct {
+		Password string `validate:"sensitive, trim, maxlen(6)"`
+	}
+	a := Account{Password: " " + testSecretValue}
+	changes, err := Sanitize(&a)
+	assert.NoError(t, err)
+	assert.Equal(t, "hunter", a.Password)
+	assert.Len(t, changes, 2)
+	for _, c := range changes {
+		assert.Equal(t, Redacted, c.Old)
+		assert.Equal(t, Redacted, c.New)
+	}
+}
+
+func TestStdLen_NonString(t *testing.T) {
+	type TestStruct struct {
+		Pin int `validate:"len(4)"`
+	}
+	// the reason names the type, not the value
+	err := Validate(TestStruct{Pin: 1234})
+	assert.EqualError(t, err, `Validation failed for field "Pin": unexpected string type: int`)
+	var usage *UsageError
+	assert.True(t, errors.As(err, &usage))
+}
@@ -0,0 +1,271 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ControlHandle is the Change handle of stripped control characters.
+const ControlHandle = "control"
+
+// Change records a field modified by Sanitize.
+type Change struct {
+	Field   string
+	Path    string
+	Pointer string
+	// Handle is the normalizer that changed the value, the validator it was
+	// fixed for, or ControlHandle
+	Handle string
+	Old    interface{}
+	New    interface{}
+}
+
+// sanitizeFunc fixes v to satisfy the validator it is registered for. It
+// reports whether v was changed.
+type sanitizeFunc func(v reflect.Value, rules *ruleSet, args ...string) (bool, error)
+
+var sanitizers = map[string]sanitizeFunc{
+	"enum":   sanitizeEnum,
+	"gte":    sanitizeBound(CompareLessThan),
+	"lte":    sanitizeBound(CompareGreaterThan),
+	"maxlen": sanitizeMaxLen,
+	"range":  sanitizeRange,
+}
+
+// Sanitize normalizes the value datum points to and then fixes the fields
+// that can be fixed instead of rejecting them: numbers are clamped into
+// range, gte and lte, strings are truncated to maxlen at a rune boundary,
+// unknown enum values are replaced with the default of the field or else
+// the first enum option, and the control characters other than tab and
+// newline are stripped from every string. A zero field with optional in its
+// chain is left alone.
+//
+// Every modification, including the ones made by the normalizers, is
+// reported in the returned changes. Sanitize does not check the value, call
+// Validate for that.
+func Sanitize(datum interface{}, opts ...Option) ([]Change, error) {
+	rv := reflect.ValueOf(datum)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return nil, fmt.Errorf("Sanitize accepts a non-nil pointer, %T given", datum)
+	}
+	s := newValidation(context.Background(), opts...)
+	s.audit = true
+	if err := s.walkPass(passNormalize, datum); err != nil {
+		return nil, err
+	}
+	if err := s.walkPass(passSanitize, datum); err != nil {
+		return nil, err
+	}
+	return s.changes, nil
+}
+
+func (s *validation) sanitizeField(name string, path fieldPath, v reflect.Value, rules *ruleSet) error {
+	if rules.has("optional") && v.IsZero() {
+		return nil
+	}
+	old := snapshot(v)
+	record := func(handle string) {
+		old = s.record(name, path, handle, old, v, rules.sensitive)
+	}
+
+	if sv := stringValue(v); sv.IsValid() {
+		if clean := stripControl(sv.String()); clean != sv.String() {
+			sv.SetString(clean)
+			record(ControlHandle)
+		}
+	}
+	for _, tag := range rules.tags {
+		fn, ok := sanitizers[tag.Op]
+		if !ok {
+			continue
+		}
+		args := make([]string, 0, len(tag.Args))
+		for _, arg := range tag.Args {
+			args = append(args, fmt.Sprint(arg))
+		}
+		changed, err := fn(v, rules, args...)
+		if err != nil {
+			return fmt.Errorf("Sanitizer %q failed for field %q: %s", tag.Op, name, err)
+		}
+		if changed {
+			record(tag.Op)
+		}
+	}
+	return nil
+}
+
+// record adds a change of the field from old to the current value of v and
+// returns the latter. The values of a sensitive field are reported as
+// Redacted.
+func (s *validation) record(name string, path fieldPath, handle string, old interface{}, v reflect.Value, sensitive bool) interface{} {
+	cur := snapshot(v)
+	change := Change{
+		Field:   name,
+		Path:    path.path,
+		Pointer: path.pointer,
+		Handle:  handle,
+		Old:     old,
+		New:     cur,
+	}
+	if sensitive {
+		change.Old, change.New = Redacted, Redacted
+	}
+	s.changes = append(s.changes, change)
+	return cur
+}
+
+// snapshot returns a copy of the value v holds or points to that is not
+// affected by the later changes to v.
+func snapshot(v reflect.Value) interface{} {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Slice && !v.IsNil() {
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(c, v)
+		return c.Interface()
+	}
+	return v.Interface()
+}
+
+// stringValue returns the string v holds or points to, or an invalid value.
+func stringValue(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.String {
+		return reflect.Value{}
+	}
+	return v
+}
+
+// numericValue returns the number v holds or points to, or an invalid value.
+func numericValue(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	if !isNumeric(v.Type()) {
+		return reflect.Value{}
+	}
+	return v
+}
+
+func stripControl(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) && r != '\t' && r != '\n' {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// setArg sets v to the tag argument converted to the type of v.
+func setArg(v reflect.Value, arg string) error {
+	if v.Kind() == reflect.Interface {
+		// the dynamic type to convert the argument to is unknown
+		return fmt.Errorf("cannot set a value of interface type %v", v.Type())
+	}
+	val, err := convStringVal(arg, v.Kind())
+	if err != nil {
+		return err
+	}
+	if !val.Type().ConvertibleTo(v.Type()) {
+		return fmt.Errorf("cannot use %v as %v", val.Type(), v.Type())
+	}
+	v.Set(val.Convert(v.Type()))
+	return nil
+}
+
+// sanitizeBound replaces a number comparing to the bound as out with the
+// bound.
+func sanitizeBound(out Equality) sanitizeFunc {
+	return func(v reflect.Value, rules *ruleSet, args ...string) (bool, error) {
+		if len(args) != 1 {
+			return false, fmt.Errorf("want 1 argument, got %d", len(args))
+		}
+		nv := numericValue(v)
+		if !nv.IsValid() {
+			return false, nil
+		}
+		eq, err := compare(nv.Interface(), args[0])
+		if err != nil || eq != out {
+			return false, err
+		}
+		return true, setArg(nv, args[0])
+	}
+}
+
+func sanitizeRange(v reflect.Value, rules *ruleSet, args ...string) (bool, error) {
+	if len(args) != 2 {
+		return false, fmt.Errorf("want 2 arguments, got %d", len(args))
+	}
+	low, err := sanitizeBound(CompareLessThan)(v, rules, args[0])
+	if err != nil || low {
+		return low, err
+	}
+	return sanitizeBound(CompareGreaterThan)(v, rules, args[1])
+}
+
+func sanitizeMaxLen(v reflect.Value, rules *ruleSet, args ...string) (bool, error) {
+	if len(args) != 1 {
+		return false, fmt.Errorf("want 1 argument, got %d", len(args))
+	}
+	sv := stringValue(v)
+	if !sv.IsValid() {
+		return false, nil
+	}
+	max, err := convStringVal(args[0], reflect.Int)
+	if err != nil {
+		return false, err
+	}
+	s := sv.String()
+	n := int(max.Int())
+	if len(s) <= n {
+		return false, nil
+	}
+	// step back to the start of the rune crossing the limit
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	sv.SetString(s[:n])
+	return true, nil
+}
+
+func sanitizeEnum(v reflect.Value, rules *ruleSet, args ...string) (bool, error) {
+	if len(args) == 0 {
+		return false, nil
+	}
+	ev := v
+	for ev.Kind() == reflect.Ptr {
+		if ev.IsNil() {
+			return false, nil
+		}
+		ev = ev.Elem()
+	}
+	if ok, err := StdEnum(ev.Interface(), args...); ok {
+		return false, nil
+	} else if _, usage := err.(*UsageError); usage {
+		// the enum does not apply to the value, it is not a mismatch
+		return false, err
+	}
+	def := args[0]
+	for _, tag := range rules.normalize {
+		if tag.Op == "default" && len(tag.Args) == 1 {
+			def = fmt.Sprint(tag.Args[0])
+		}
+	}
+	return true, setArg(ev, def)
+}
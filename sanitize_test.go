@@ -0,0 +1,125 @@
+package validator
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitize(t *testing.T) {
+	type Item struct {
+		Qty int `json:"qty" validate:"range(1, 10)"`
+	}
+	type Record struct {
+		Title    string   `json:"title" validate:"trim, maxlen(8)"`
+		Rating   float64  `json:"rating" validate:"gte(0), lte(5)"`
+		Kind     string   `json:"kind" validate:"default(text), enum(text, audio)"`
+		Level    string   `json:"level" validate:"enum(low, high)"`
+		Discount int      `json:"discount" validate:"optional, range(5, 50)"`
+		Note     string   `json:"note" validate:"maxlen(4)"`
+		Items    []Item   `json:"items"`
+		Tags     []string `json:"tags"`
+	}
+
+	r := Record{
+		Title:  "  naïve café au lait ",
+		Rating: 7.5,
+		Kind:   "video",
+		Level:  "mid",
+		Note:   "ab\x00cdef",
+		Items:  []Item{{Qty: 3}, {Qty: 0}, {Qty: 11}},
+	}
+	changes, err := Sanitize(&r)
+	assert.NoError(t, err)
+
+	assert.Equal(t, Record{
+		Title:  "naïve c",
+		Rating: 5,
+		Kind:   "text",
+		Level:  "low",
+		Note:   "abcd",
+		Items:  []Item{{Qty: 3}, {Qty: 1}, {Qty: 10}},
+	}, r)
+	assert.NoError(t, Validate(&r))
+
+	type change struct {
+		Pointer string
+		Handle  string
+		Old     interface{}
+		New     interface{}
+	}
+	got := make([]change, 0, len(changes))
+	for _, c := range changes {
+		got = append(got, change{c.Pointer, c.Handle, c.Old, c.New})
+	}
+	assert.Equal(t, []change{
+		{"/title", "trim", "  naïve café au lait ", "naïve café au lait"},
+		{"/title", "maxlen", "naïve café au lait", "naïve c"},
+		{"/rating", "lte", 7.5, 5.0},
+		{"/kind", "enum", "video", "text"},
+		{"/level", "enum", "mid", "low"},
+		{"/note", ControlHandle, "ab\x00cdef", "abcdef"},
+		{"/note", "maxlen", "abcdef", "abcd"},
+		{"/items/1/qty", "range", 0, 1},
+		{"/items/2/qty", "range", 11, 10},
+	}, got)
+}
+
+func TestSanitize_Control(t *testing.T) {
+	type Record struct {
+		Body string `validate:"nonempty"`
+	}
+	r := Record{Body: "line\x1b[31m\r\n\tnext\x7f"}
+	changes, err := Sanitize(&r)
+	assert.NoError(t, err)
+	assert.Equal(t, "line[31m\n\tnext", r.Body)
+	assert.Equal(t, []Change{{
+		Field:   "Body",
+		Path:    "Body",
+		Pointer: "/Body",
+		Handle:  ControlHandle,
+		Old:     "line\x1b[31m\r\n\tnext\x7f",
+		New:     "line[31m\n\tnext",
+	}}, changes)
+}
+
+type testSanitizeKind string
+
+func (k testSanitizeKind) String() string { return string(k) }
+
+func TestSanitize_Errors(t *testing.T) {
+	type Record struct {
+		Kind string `validate:"enum(a, b)"`
+	}
+	_, err := Sanitize(Record{})
+	assert.EqualError(t, err, "Sanitize accepts a non-nil pointer, validator.Record given")
+
+	type BadRange struct {
+		Size int `validate:"range(1, many)"`
+	}
+	_, err = Sanitize(&BadRange{Size: 5})
+	assert.EqualError(t, err, `Sanitizer "range" failed for field "Size": strconv.ParseInt: parsing "many": invalid syntax`)
+
+	type Stringer struct {
+		Kind fmt.Stringer `validate:"enum(a, b)"`
+	}
+	v := Stringer{Kind: time.Duration(5)}
+	_, err = Sanitize(&v)
+	assert.EqualError(t, err, `Sanitizer "enum" failed for field "Kind": strconv.ParseInt: parsing "a": invalid syntax`)
+	assert.Equal(t, time.Duration(5), v.Kind)
+
+	v = Stringer{Kind: testSanitizeKind("c")}
+	_, err = Sanitize(&v)
+	assert.EqualError(t, err, `Sanitizer "enum" failed for field "Kind": cannot set a value of interface type fmt.Stringer`)
+	assert.Equal(t, testSanitizeKind("c"), v.Kind)
+
+	type Unsupported struct {
+		Tags []string `validate:"enum(a, b)"`
+	}
+	u := Unsupported{Tags: []string{"c"}}
+	_, err = Sanitize(&u)
+	assert.Error(t, err)
+	assert.Equal(t, []string{"c"}, u.Tags)
+}
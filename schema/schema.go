@@ -0,0 +1,665 @@
+// Package schema validates JSON documents against a JSON Schema using the
+// validator engine.
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/osdrv/validator"
+)
+
+// Schema is a compiled JSON Schema document.
+type Schema struct {
+	root *node
+}
+
+type node struct {
+	// always is set for the boolean schemas true and false
+	always *bool
+	types  []string
+	enum   []interface{}
+	// the validator chains applied to the values of the matching JSON type,
+	// nil without the keywords
+	number, str, array, object *validator.Chain
+	pattern                    *regexp.Regexp
+	unique                     bool
+	properties                 map[string]*node
+	additional                 *node
+	required                   []string
+	items                      *node
+	allOf, anyOf, oneOf        []*node
+	not                        *node
+	ref                        string
+	target                     *node
+}
+
+// Compile compiles a JSON Schema document. The supported keywords are type,
+// enum, const, minimum, maximum, exclusiveMinimum, exclusiveMaximum,
+// minLength, maxLength, pattern, items, minItems, maxItems, uniqueItems,
+// properties, required, additionalProperties, minProperties, maxProperties,
+// allOf, anyOf, oneOf, not and $ref within the document. Other keywords are
+// ignored.
+func Compile(doc []byte) (*Schema, error) {
+	var root interface{}
+	if err := json.Unmarshal(doc, &root); err != nil {
+		return nil, fmt.Errorf("Malformed JSON Schema: %s", err)
+	}
+	c := &compiler{
+		doc:   root,
+		nodes: make(map[string]*node),
+	}
+	n, err := c.compile(root, "")
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(c.refs); i++ {
+		// resolving a reference may compile more of them
+		if err := c.resolve(c.refs[i]); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.checkCycles(); err != nil {
+		return nil, err
+	}
+	return &Schema{root: n}, nil
+}
+
+// Validate validates a JSON document given as []byte or json.RawMessage, or
+// a value decoded by encoding/json. The options are passed to the
+// validators. The error of a failed keyword is a *validator.FieldError with
+// the Handle set to the keyword or to the validator it is checked with. An
+// Observer sees the whole document as one validation.
+func (s *Schema) Validate(data interface{}, opts ...validator.Option) error {
+	return validator.Observed(data, func() error {
+		switch d := data.(type) {
+		case []byte:
+			return s.validateJSON(d, opts)
+		case json.RawMessage:
+			return s.validateJSON(d, opts)
+		}
+		return s.root.validate(data, path{}, opts)
+	}, opts...)
+}
+
+func (s *Schema) validateJSON(doc []byte, opts []validator.Option) error {
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	if err := dec.Decode(&v); err != nil {
+		return fmt.Errorf("Malformed JSON document: %s", err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return errors.New("Malformed JSON document: unexpected data after the top-level value")
+	}
+	return s.root.validate(v, path{}, opts)
+}
+
+type compiler struct {
+	doc   interface{}
+	nodes map[string]*node
+	refs  []*node
+}
+
+func (c *compiler) compile(v interface{}, ptr string) (*node, error) {
+	if n, ok := c.nodes[ptr]; ok {
+		return n, nil
+	}
+	n := &node{}
+	c.nodes[ptr] = n
+	if b, ok := v.(bool); ok {
+		n.always = &b
+		return n, nil
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("Schema at %q should be an object or a boolean, %T found", "#"+ptr, v)
+	}
+	kw := keywords{m: m, ptr: ptr}
+
+	switch t := m["type"].(type) {
+	case nil:
+	case string:
+		n.types = []string{t}
+	case []interface{}:
+		for _, tt := range t {
+			s, ok := tt.(string)
+			if !ok {
+				return nil, kw.errorf("type", "want a string or an array of strings")
+			}
+			n.types = append(n.types, s)
+		}
+	default:
+		return nil, kw.errorf("type", "want a string or an array of strings")
+	}
+	if enum, ok := m["enum"]; ok {
+		if n.enum, ok = enum.([]interface{}); !ok {
+			return nil, kw.errorf("enum", "want an array")
+		}
+	}
+	if cv, ok := m["const"]; ok {
+		n.enum = []interface{}{cv}
+	}
+
+	var err error
+	if n.number, err = kw.chain(map[string]string{
+		"minimum":          "gte",
+		"maximum":          "lte",
+		"exclusiveMinimum": "gt",
+		"exclusiveMaximum": "lt",
+	}, false); err != nil {
+		return nil, err
+	}
+	if n.str, err = kw.chain(map[string]string{
+		// JSON Schema counts the code points
+		"minLength": "minrunes",
+		"maxLength": "maxrunes",
+	}, true); err != nil {
+		return nil, err
+	}
+	if n.array, err = kw.chain(map[string]string{
+		"minItems": "minitems",
+		"maxItems": "maxitems",
+	}, true); err != nil {
+		return nil, err
+	}
+	if n.object, err = kw.chain(map[string]string{
+		"minProperties": "minitems",
+		"maxProperties": "maxitems",
+	}, true); err != nil {
+		return nil, err
+	}
+	if p, ok := m["pattern"]; ok {
+		s, ok := p.(string)
+		if !ok {
+			return nil, kw.errorf("pattern", "want a string")
+		}
+		if n.pattern, err = regexp.Compile(s); err != nil {
+			return nil, kw.errorf("pattern", "%s", err)
+		}
+	}
+	if u, ok := m["uniqueItems"]; ok {
+		if n.unique, ok = u.(bool); !ok {
+			return nil, kw.errorf("uniqueItems", "want a boolean")
+		}
+	}
+
+	if props, ok := m["properties"]; ok {
+		pm, ok := props.(map[string]interface{})
+		if !ok {
+			return nil, kw.errorf("properties", "want an object")
+		}
+		n.properties = make(map[string]*node, len(pm))
+		for name, sub := range pm {
+			if n.properties[name], err = c.compile(sub, ptr+"/properties/"+escapePointer(name)); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if req, ok := m["required"]; ok {
+		names, ok := req.([]interface{})
+		if !ok {
+			return nil, kw.errorf("required", "want an array of strings")
+		}
+		for _, name := range names {
+			s, ok := name.(string)
+			if !ok {
+				return nil, kw.errorf("required", "want an array of strings")
+			}
+			n.required = append(n.required, s)
+		}
+	}
+	for key, dst := range map[string]**node{
+		"additionalProperties": &n.additional,
+		"items":                &n.items,
+		"not":                  &n.not,
+	} {
+		if sub, ok := m[key]; ok {
+			if *dst, err = c.compile(sub, ptr+"/"+key); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for key, dst := range map[string]*[]*node{
+		"allOf": &n.allOf,
+		"anyOf": &n.anyOf,
+		"oneOf": &n.oneOf,
+	} {
+		sub, ok := m[key]
+		if !ok {
+			continue
+		}
+		subs, ok := sub.([]interface{})
+		if !ok || len(subs) == 0 {
+			return nil, kw.errorf(key, "want a non-empty array")
+		}
+		for i, s := range subs {
+			sn, err := c.compile(s, fmt.Sprintf("%s/%s/%d", ptr, key, i))
+			if err != nil {
+				return nil, err
+			}
+			*dst = append(*dst, sn)
+		}
+	}
+
+	if ref, ok := m["$ref"]; ok {
+		if n.ref, ok = ref.(string); !ok {
+			return nil, kw.errorf("$ref", "want a string")
+		}
+		if !strings.HasPrefix(n.ref, "#") {
+			return nil, kw.errorf("$ref", "only references within the document are supported, %q given", n.ref)
+		}
+		c.refs = append(c.refs, n)
+	}
+
+	return n, nil
+}
+
+func (c *compiler) resolve(n *node) error {
+	ptr, err := url.PathUnescape(strings.TrimPrefix(n.ref, "#"))
+	if err != nil {
+		return fmt.Errorf("Malformed $ref %q: %s", n.ref, err)
+	}
+	v := c.doc
+	if ptr != "" {
+		for _, seg := range strings.Split(strings.TrimPrefix(ptr, "/"), "/") {
+			seg = strings.NewReplacer("~1", "/", "~0", "~").Replace(seg)
+			switch d := v.(type) {
+			case map[string]interface{}:
+				next, ok := d[seg]
+				if !ok {
+					return fmt.Errorf("Unresolvable $ref %q", n.ref)
+				}
+				v = next
+			case []interface{}:
+				ix, err := strconv.Atoi(seg)
+				if err != nil || ix < 0 || ix >= len(d) {
+					return fmt.Errorf("Unresolvable $ref %q", n.ref)
+				}
+				v = d[ix]
+			default:
+				return fmt.Errorf("Unresolvable $ref %q", n.ref)
+			}
+		}
+	}
+	n.target, err = c.compile(v, ptr)
+	return err
+}
+
+// checkCycles rejects the schemas applying themselves to the same value, like
+// two definitions referring to each other, which would never stop
+// validating.
+func (c *compiler) checkCycles() error {
+	ptrs := make([]string, 0, len(c.nodes))
+	names := make(map[*node]string, len(c.nodes))
+	for ptr, n := range c.nodes {
+		ptrs = append(ptrs, ptr)
+		names[n] = ptr
+	}
+	sort.Strings(ptrs)
+	const (
+		visiting = iota + 1
+		done
+	)
+	state := make(map[*node]int, len(c.nodes))
+	// visit returns a node on a cycle reachable from n, if any
+	var visit func(n *node) *node
+	visit = func(n *node) *node {
+		switch state[n] {
+		case visiting:
+			return n
+		case done:
+			return nil
+		}
+		state[n] = visiting
+		for _, sub := range n.inPlace() {
+			if cyc := visit(sub); cyc != nil {
+				return cyc
+			}
+		}
+		state[n] = done
+		return nil
+	}
+	for _, ptr := range ptrs {
+		if cyc := visit(c.nodes[ptr]); cyc != nil {
+			return fmt.Errorf("Circular $ref at %q: the schema applies itself to the same value", "#"+names[cyc])
+		}
+	}
+	return nil
+}
+
+// inPlace returns the subschemas applied to the same value as n.
+func (n *node) inPlace() []*node {
+	subs := make([]*node, 0, len(n.allOf)+len(n.anyOf)+len(n.oneOf)+2)
+	if n.target != nil {
+		subs = append(subs, n.target)
+	}
+	if n.not != nil {
+		subs = append(subs, n.not)
+	}
+	subs = append(subs, n.allOf...)
+	subs = append(subs, n.anyOf...)
+	return append(subs, n.oneOf...)
+}
+
+type keywords struct {
+	m   map[string]interface{}
+	ptr string
+}
+
+func (k keywords) errorf(key string, format string, args ...interface{}) error {
+	return fmt.Errorf("Keyword %q at %q: %s", key, "#"+k.ptr, fmt.Sprintf(format, args...))
+}
+
+// chain builds a validator chain from the numeric keywords mapped to the
+// validator handles.
+func (k keywords) chain(handles map[string]string, integer bool) (*validator.Chain, error) {
+	keys := make([]string, 0, len(handles))
+	for key := range handles {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	rules := make([]string, 0, len(keys))
+	for _, key := range keys {
+		v, ok := k.m[key]
+		if !ok {
+			continue
+		}
+		f, ok := v.(float64)
+		if !ok || integer && (f < 0 || f != float64(int(f))) {
+			if integer {
+				return nil, k.errorf(key, "want a non-negative integer")
+			}
+			return nil, k.errorf(key, "want a number")
+		}
+		rules = append(rules, fmt.Sprintf("%s(%s)", handles[key], strconv.FormatFloat(f, 'g', -1, 64)))
+	}
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	return validator.ParseChain(strings.Join(rules, ", "))
+}
+
+type path struct {
+	name    string
+	path    string
+	pointer string
+}
+
+func (p path) field(name string) path {
+	fp := name
+	if p.path != "" {
+		fp = p.path + "." + name
+	}
+	return path{
+		name:    name,
+		path:    fp,
+		pointer: p.pointer + "/" + escapePointer(name),
+	}
+}
+
+func (p path) index(ix int) path {
+	return path{
+		name:    p.name,
+		path:    fmt.Sprintf("%s[%d]", p.path, ix),
+		pointer: fmt.Sprintf("%s/%d", p.pointer, ix),
+	}
+}
+
+func escapePointer(s string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(s)
+}
+
+func fail(p path, handle string, value interface{}, reason string) error {
+	return &validator.FieldError{
+		Field:   p.name,
+		Path:    p.path,
+		Pointer: p.pointer,
+		Handle:  handle,
+		Value:   value,
+		Reason:  reason,
+		Message: reason,
+	}
+}
+
+func (n *node) validate(v interface{}, p path, opts []validator.Option) error {
+	if n.always != nil {
+		if !*n.always {
+			return fail(p, "false", v, "is not allowed")
+		}
+		return nil
+	}
+	if n.target != nil {
+		if err := n.target.validate(v, p, opts); err != nil {
+			return err
+		}
+	}
+
+	v = normalize(v)
+	typ := jsonType(v)
+	if len(n.types) > 0 && !n.hasType(typ) {
+		return fail(p, "type", v, fmt.Sprintf("should be of type %s", strings.Join(n.types, " or ")))
+	}
+	if n.enum != nil && !n.inEnum(v) {
+		if len(n.enum) == 1 {
+			return fail(p, "enum", v, fmt.Sprintf("should be equal to %v", n.enum[0]))
+		}
+		return fail(p, "enum", v, fmt.Sprintf("should be one of %v", n.enum))
+	}
+
+	switch typ {
+	case "integer", "number":
+		if err := check(v, n.number, p, opts); err != nil {
+			return err
+		}
+	case "string":
+		if err := check(v, n.str, p, opts); err != nil {
+			return err
+		}
+		if n.pattern != nil && !n.pattern.MatchString(v.(string)) {
+			return fail(p, "pattern", v, fmt.Sprintf("should match pattern %q", n.pattern))
+		}
+	case "array":
+		if err := n.validateArray(v.([]interface{}), p, opts); err != nil {
+			return err
+		}
+	case "object":
+		if err := n.validateObject(v.(map[string]interface{}), p, opts); err != nil {
+			return err
+		}
+	}
+
+	for _, sub := range n.allOf {
+		if err := sub.validate(v, p, opts); err != nil {
+			return err
+		}
+	}
+	if len(n.anyOf) > 0 {
+		var first error
+		for i, sub := range n.anyOf {
+			err := sub.validate(v, p, opts)
+			if err == nil {
+				break
+			}
+			if i == 0 {
+				first = err
+			}
+			if i == len(n.anyOf)-1 {
+				return fail(p, "anyOf", v, fmt.Sprintf("should match at least one schema: %s", reason(first, p)))
+			}
+		}
+	}
+	if len(n.oneOf) > 0 {
+		matched := 0
+		for _, sub := range n.oneOf {
+			if sub.validate(v, p, opts) == nil {
+				matched++
+			}
+		}
+		if matched != 1 {
+			return fail(p, "oneOf", v, fmt.Sprintf("should match exactly one schema, %d matched", matched))
+		}
+	}
+	if n.not != nil && n.not.validate(v, p, opts) == nil {
+		return fail(p, "not", v, "should not match the schema")
+	}
+	return nil
+}
+
+func (n *node) validateArray(v []interface{}, p path, opts []validator.Option) error {
+	if err := check(v, n.array, p, opts); err != nil {
+		return err
+	}
+	if n.unique {
+		for i := 1; i < len(v); i++ {
+			for j := 0; j < i; j++ {
+				if reflect.DeepEqual(v[i], v[j]) {
+					return fail(p, "uniqueItems", v, fmt.Sprintf("element at index %d duplicates element at index %d", i, j))
+				}
+			}
+		}
+	}
+	if n.items != nil {
+		for i, elem := range v {
+			if err := n.items.validate(elem, p.index(i), opts); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (n *node) validateObject(v map[string]interface{}, p path, opts []validator.Option) error {
+	if err := check(v, n.object, p, opts); err != nil {
+		return err
+	}
+	for _, name := range n.required {
+		if _, ok := v[name]; !ok {
+			return fail(p.field(name), "required", nil, "is required")
+		}
+	}
+	names := make([]string, 0, len(v))
+	for name := range v {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		sub, ok := n.properties[name]
+		if !ok {
+			sub = n.additional
+		}
+		if sub == nil {
+			continue
+		}
+		if err := sub.validate(v[name], p.field(name), opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *node) hasType(typ string) bool {
+	for _, t := range n.types {
+		if t == typ || t == "number" && typ == "integer" {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *node) inEnum(v interface{}) bool {
+	for _, e := range n.enum {
+		if reflect.DeepEqual(v, normalize(e)) {
+			return true
+		}
+	}
+	return false
+}
+
+// check runs the validator chain against v.
+func check(v interface{}, chain *validator.Chain, p path, opts []validator.Option) error {
+	if chain == nil {
+		return nil
+	}
+	err := chain.Validate(v, opts...)
+	var fe *validator.FieldError
+	if errors.As(err, &fe) {
+		return &validator.FieldError{
+			Field:   p.name,
+			Path:    p.path,
+			Pointer: p.pointer,
+			Handle:  fe.Handle,
+			Alias:   fe.Alias,
+			Args:    fe.Args,
+			Value:   fe.Value,
+			Reason:  fe.Reason,
+			Message: fe.Message,
+			Err:     fe.Err,
+		}
+	}
+	return err
+}
+
+// reason describes err relative to the value at p.
+func reason(err error, p path) string {
+	var fe *validator.FieldError
+	if errors.As(err, &fe) {
+		if fe.Pointer == p.pointer {
+			return fe.Message
+		}
+		return fmt.Sprintf("%s %s", fe.Path, fe.Message)
+	}
+	return err.Error()
+}
+
+// normalize converts the numbers of any Go type to float64 the same way
+// encoding/json decodes them.
+func normalize(v interface{}) interface{} {
+	switch n := v.(type) {
+	case json.Number:
+		if f, err := n.Float64(); err == nil {
+			return f
+		}
+		return v
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	}
+	return v
+}
+
+func jsonType(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if math.Trunc(t) == t {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
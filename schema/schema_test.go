@@ -0,0 +1,306 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/osdrv/validator"
+	"github.com/stretchr/testify/assert"
+)
+
+const testOrderSchema = `{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"type": "object",
+	"required": ["id", "customer", "items"],
+	"properties": {
+		"id": {"type": "string", "pattern": "^ord-[0-9]+$"},
+		"status": {"enum": ["new", "paid", "shipped"]},
+		"customer": {"$ref": "#/$defs/customer"},
+		"items": {
+			"type": "array",
+			"minItems": 1,
+			"maxItems": 3,
+			"items": {"$ref": "#/$defs/item"}
+		},
+		"tags": {"type": "array", "uniqueItems": true, "items": {"type": "string", "minLength": 2, "maxLength": 8}},
+		"note": {"anyOf": [{"type": "null"}, {"type": "string", "maxLength": 10}]},
+		"discount": {"oneOf": [
+			{"type": "number", "exclusiveMinimum": 0, "maximum": 1},
+			{"type": "integer", "minimum": 1}
+		]}
+	},
+	"additionalProperties": false,
+	"$defs": {
+		"customer": {
+			"type": "object",
+			"required": ["email"],
+			"properties": {
+				"email": {"type": "string", "minLength": 3},
+				"referrer": {"$ref": "#/$defs/customer"}
+			}
+		},
+		"item": {
+			"type": "object",
+			"required": ["sku", "qty"],
+			"properties": {
+				"sku": {"type": "string"},
+				"qty": {"type": "integer", "minimum": 1, "maximum": 100},
+				"price": {"allOf": [{"type": "number"}, {"exclusiveMinimum": 0}]}
+			}
+		}
+	}
+}`
+
+func TestSchema_Validate(t *testing.T) {
+	s, err := Compile([]byte(testOrderSchema))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		doc         string
+		wantErr     string
+		wantHandle  string
+		wantPointer string
+	}{
+		{
+			name: "valid",
+			doc: `{"id": "ord-1", "status": "paid", "customer": {"email": "a@b.c", "referrer": {"email": "x@y.z"}},
+				"items": [{"sku": "a", "qty": 2, "price": 9.5}], "tags": ["aa", "bb"], "note": null, "discount": 0.5}`,
+		},
+		{
+			name:        "missing required",
+			doc:         `{"id": "ord-1", "items": [{"sku": "a", "qty": 1}]}`,
+			wantErr:     `Validation failed for field "customer": is required`,
+			wantHandle:  "required",
+			wantPointer: "/customer",
+		},
+		{
+			name:        "pattern",
+			doc:         `{"id": "1", "customer": {"email": "a@b.c"}, "items": [{"sku": "a", "qty": 1}]}`,
+			wantErr:     `Validation failed for field "id": should match pattern "^ord-[0-9]+$"`,
+			wantHandle:  "pattern",
+			wantPointer: "/id",
+		},
+		{
+			name:        "enum",
+			doc:         `{"id": "ord-1", "status": "lost", "customer": {"email": "a@b.c"}, "items": [{"sku": "a", "qty": 1}]}`,
+			wantErr:     `Validation failed for field "status": should be one of [new paid shipped]`,
+			wantHandle:  "enum",
+			wantPointer: "/status",
+		},
+		{
+			name:        "recursive ref",
+			doc:         `{"id": "ord-1", "customer": {"email": "a@b.c", "referrer": {"email": "x"}}, "items": [{"sku": "a", "qty": 1}]}`,
+			wantErr:     `Validation failed for field "email": length must be at least 3 characters`,
+			wantHandle:  "minrunes",
+			wantPointer: "/customer/referrer/email",
+		},
+		{
+			name: "non-ASCII lengths",
+			doc:  `{"id": "ord-1", "customer": {"email": "é@ü"}, "items": [{"sku": "a", "qty": 1}], "tags": ["hé", "日本語テキスト"]}`,
+		},
+		{
+			name:        "non-ASCII max length",
+			doc:         `{"id": "ord-1", "customer": {"email": "a@b.c"}, "items": [{"sku": "a", "qty": 1}], "tags": ["日本語テキストです"]}`,
+			wantErr:     `Validation failed for field "tags": length must be up to 8 characters`,
+			wantHandle:  "maxrunes",
+			wantPointer: "/tags/0",
+		},
+		{
+			name:        "min items",
+			doc:         `{"id": "ord-1", "customer": {"email": "a@b.c"}, "items": []}`,
+			wantErr:     `Validation failed for field "items": should contain at least 1 items`,
+			wantHandle:  "minitems",
+			wantPointer: "/items",
+		},
+		{
+			name:        "item type",
+			doc:         `{"id": "ord-1", "customer": {"email": "a@b.c"}, "items": [{"sku": "a", "qty": 1}, {"sku": "b", "qty": 1.5}]}`,
+			wantErr:     `Validation failed for field "qty": should be of type integer`,
+			wantHandle:  "type",
+			wantPointer: "/items/1/qty",
+		},
+		{
+			name:        "maximum",
+			doc:         `{"id": "ord-1", "customer": {"email": "a@b.c"}, "items": [{"sku": "a", "qty": 101}]}`,
+			wantErr:     `Validation failed for field "qty": should be less or equal to 100`,
+			wantHandle:  "lte",
+			wantPointer: "/items/0/qty",
+		},
+		{
+			name:        "allOf",
+			doc:         `{"id": "ord-1", "customer": {"email": "a@b.c"}, "items": [{"sku": "a", "qty": 1, "price": 0}]}`,
+			wantErr:     `Validation failed for field "price": should be greater than 0`,
+			wantHandle:  "gt",
+			wantPointer: "/items/0/price",
+		},
+		{
+			name:        "unique items",
+			doc:         `{"id": "ord-1", "customer": {"email": "a@b.c"}, "items": [{"sku": "a", "qty": 1}], "tags": ["aa", "aa"]}`,
+			wantErr:     `Validation failed for field "tags": element at index 1 duplicates element at index 0`,
+			wantHandle:  "uniqueItems",
+			wantPointer: "/tags",
+		},
+		{
+			name:        "anyOf",
+			doc:         `{"id": "ord-1", "customer": {"email": "a@b.c"}, "items": [{"sku": "a", "qty": 1}], "note": "way too long"}`,
+			wantErr:     `Validation failed for field "note": should match at least one schema: should be of type null`,
+			wantHandle:  "anyOf",
+			wantPointer: "/note",
+		},
+		{
+			name:        "oneOf",
+			doc:         `{"id": "ord-1", "customer": {"email": "a@b.c"}, "items": [{"sku": "a", "qty": 1}], "discount": 1}`,
+			wantErr:     `Validation failed for field "discount": should match exactly one schema, 2 matched`,
+			wantHandle:  "oneOf",
+			wantPointer: "/discount",
+		},
+		{
+			name:        "additional properties",
+			doc:         `{"id": "ord-1", "customer": {"email": "a@b.c"}, "items": [{"sku": "a", "qty": 1}], "extra": 1}`,
+			wantErr:     `Validation failed for field "extra": is not allowed`,
+			wantHandle:  "false",
+			wantPointer: "/extra",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.Validate(json.RawMessage(tt.doc))
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+			fe, ok := err.(*validator.FieldError)
+			if assert.True(t, ok) {
+				assert.Equal(t, tt.wantHandle, fe.Handle)
+				assert.Equal(t, tt.wantPointer, fe.Pointer)
+			}
+		})
+	}
+}
+
+func TestSchema_ValidateDecoded(t *testing.T) {
+	s, err := Compile([]byte(`{"type": "array", "items": {"type": "integer", "maximum": 10}}`))
+	assert.NoError(t, err)
+
+	assert.NoError(t, s.Validate([]interface{}{1, 2.0, int64(10)}))
+
+	err = s.Validate([]interface{}{1, 11})
+	assert.EqualError(t, err, "Validation failed: should be less or equal to 10")
+	assert.Equal(t, "[1]", err.(*validator.FieldError).Path)
+
+	assert.Error(t, s.Validate([]byte(`[1,`)))
+	assert.EqualError(t, s.Validate([]byte(`[1] [2,`)), "Malformed JSON document: unexpected data after the top-level value")
+}
+
+func TestSchema_LargeInteger(t *testing.T) {
+	s, err := Compile([]byte(`{"type": "integer"}`))
+	assert.NoError(t, err)
+
+	assert.NoError(t, s.Validate([]byte(`1e20`)))
+	assert.NoError(t, s.Validate(-1e300))
+	assert.EqualError(t, s.Validate([]byte(`1.5`)), "Validation failed: should be of type integer")
+}
+
+type testCountingObserver struct {
+	starts, checks, ends int
+}
+
+func (o *testCountingObserver) OnValidateStart(t reflect.Type)      { o.starts++ }
+func (o *testCountingObserver) OnFieldCheck(c validator.FieldCheck) { o.checks++ }
+func (o *testCountingObserver) OnValidateEnd(t reflect.Type, d time.Duration, err error) {
+	o.ends++
+}
+
+func TestSchema_Observer(t *testing.T) {
+	s, err := Compile([]byte(`{"type": "array", "maxItems": 3, "items": {"type": "integer", "minimum": 0, "maximum": 10}}`))
+	assert.NoError(t, err)
+
+	o := &testCountingObserver{}
+	assert.NoError(t, s.Validate([]byte(`[1, 2, 3]`), validator.WithObserver(o)))
+	assert.Equal(t, 1, o.starts)
+	assert.Equal(t, 7, o.checks)
+	assert.Equal(t, 1, o.ends)
+}
+
+func TestSchema_RecursiveRef(t *testing.T) {
+	s, err := Compile([]byte(`{
+		"$defs": {"node": {
+			"type": "object",
+			"properties": {
+				"name": {"type": "string", "minLength": 1},
+				"children": {"type": "array", "items": {"$ref": "#/$defs/node"}}
+			}
+		}},
+		"$ref": "#/$defs/node"
+	}`))
+	assert.NoError(t, err)
+	assert.NoError(t, s.Validate([]byte(`{"name": "a", "children": [{"name": "b", "children": []}]}`)))
+	assert.EqualError(t, s.Validate([]byte(`{"name": "a", "children": [{"name": ""}]}`)),
+		`Validation failed for field "name": length must be at least 1 characters`)
+}
+
+func TestCompile_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		wantErr string
+	}{
+		{
+			name:    "malformed",
+			schema:  `{`,
+			wantErr: "Malformed JSON Schema: unexpected end of JSON input",
+		},
+		{
+			name:    "not a schema",
+			schema:  `{"items": 1}`,
+			wantErr: `Schema at "#/items" should be an object or a boolean, float64 found`,
+		},
+		{
+			name:    "bad length",
+			schema:  `{"maxLength": -1}`,
+			wantErr: `Keyword "maxLength" at "#": want a non-negative integer`,
+		},
+		{
+			name:    "bad pattern",
+			schema:  `{"pattern": "("}`,
+			wantErr: "Keyword \"pattern\" at \"#\": error parsing regexp: missing closing ): `(`",
+		},
+		{
+			name:    "external ref",
+			schema:  `{"$ref": "other.json#/a"}`,
+			wantErr: `Keyword "$ref" at "#": only references within the document are supported, "other.json#/a" given`,
+		},
+		{
+			name:    "unresolvable ref",
+			schema:  `{"$ref": "#/$defs/missing"}`,
+			wantErr: `Unresolvable $ref "#/$defs/missing"`,
+		},
+		{
+			name:    "ref cycle",
+			schema:  `{"$defs": {"a": {"$ref": "#/$defs/b"}, "b": {"$ref": "#/$defs/a"}}, "$ref": "#/$defs/a"}`,
+			wantErr: `Circular $ref at "#/$defs/a": the schema applies itself to the same value`,
+		},
+		{
+			name:    "self ref",
+			schema:  `{"$ref": "#"}`,
+			wantErr: `Circular $ref at "#": the schema applies itself to the same value`,
+		},
+		{
+			name:    "cycle through allOf",
+			schema:  `{"$defs": {"a": {"allOf": [{"type": "object"}, {"$ref": "#/$defs/a"}]}}, "$ref": "#/$defs/a"}`,
+			wantErr: `Circular $ref at "#/$defs/a": the schema applies itself to the same value`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile([]byte(tt.schema))
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
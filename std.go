@@ -1,8 +1,11 @@
 package validator
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"reflect"
+	"unicode/utf8"
 )
 
 func StdNone() (bool, string, bool) {
@@ -10,9 +13,7 @@ func StdNone() (bool, string, bool) {
 }
 
 func StdOptional(v interface{}) (bool, string, bool) {
-	rv := reflect.ValueOf(v)
-	zv := reflect.Zero(rv.Type())
-	if reflect.DeepEqual(rv.Interface(), zv.Interface()) {
+	if isZero(v) {
 		// it's a zero value, break the validation chain
 		return true, "", Break
 	}
@@ -20,111 +21,372 @@ func StdOptional(v interface{}) (bool, string, bool) {
 }
 
 func StdEmpty(v interface{}) (bool, string) {
-	rv := reflect.ValueOf(v)
-	zv := reflect.Zero(rv.Type())
-	return reflect.DeepEqual(rv.Interface(), zv.Interface()), "should be empty"
+	return isZero(v), "should be empty"
 }
 
 func StdNonEmpty(v interface{}) (bool, string) {
+	return !isZero(v), "should not be empty"
+}
+
+func StdRequired(ctx context.Context, v interface{}) (bool, string) {
+	if field, ok := ctx.Value(ifaceFieldKey{}).(reflect.Value); ok {
+		// an interface-typed field is present when it holds any value
+		return !field.IsNil(), "is required"
+	}
 	rv := reflect.ValueOf(v)
-	zv := reflect.Zero(rv.Type())
-	return !reflect.DeepEqual(rv.Interface(), zv.Interface()), "should not be empty"
+	switch rv.Kind() {
+	case reflect.Invalid:
+		return false, "is required"
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+		return !rv.IsNil(), "is required"
+	}
+	return !isZero(v), "is required"
 }
 
-func StdEq(v interface{}, cmp string) (bool, string) {
+func StdEq(v interface{}, cmp string) (bool, error) {
 	eq, err := compare(v, cmp)
 	if err != nil {
-		return false, err.Error()
+		return false, &UsageError{Reason: err.Error()}
 	}
-	return eq == CompareEqual, fmt.Sprintf("should be equal to %s", cmp)
+	return constraint(eq == CompareEqual, fmt.Sprintf("should be equal to %s", cmp))
 }
 
-func StdNe(v interface{}, cmp string) (bool, string) {
+func StdNe(v interface{}, cmp string) (bool, error) {
 	eq, err := compare(v, cmp)
 	if err != nil {
-		return false, err.Error()
+		return false, &UsageError{Reason: err.Error()}
 	}
-	return eq != CompareEqual, fmt.Sprintf("should not be equal to %s", cmp)
+	return constraint(eq != CompareEqual, fmt.Sprintf("should not be equal to %s", cmp))
 }
 
-func StdGt(v interface{}, cmp string) (bool, string) {
+func StdGt(v interface{}, cmp string) (bool, error) {
 	eq, err := compare(v, cmp)
 	if err != nil {
-		return false, err.Error()
+		return false, &UsageError{Reason: err.Error()}
 	}
-	return eq == CompareGreaterThan, fmt.Sprintf("should be greater than %s", cmp)
+	return constraint(eq == CompareGreaterThan, fmt.Sprintf("should be greater than %s", cmp))
 }
 
-func StdGte(v interface{}, cmp string) (bool, string) {
+func StdGte(v interface{}, cmp string) (bool, error) {
 	eq, err := compare(v, cmp)
 	if err != nil {
-		return false, err.Error()
+		return false, &UsageError{Reason: err.Error()}
 	}
-	return (CompareEqual|CompareGreaterThan)&eq > 0, fmt.Sprintf("should be greater or equal to %s", cmp)
+	return constraint((CompareEqual|CompareGreaterThan)&eq > 0, fmt.Sprintf("should be greater or equal to %s", cmp))
 }
 
-func StdLt(v interface{}, cmp string) (bool, string) {
+func StdLt(v interface{}, cmp string) (bool, error) {
 	eq, err := compare(v, cmp)
 	if err != nil {
-		return false, err.Error()
+		return false, &UsageError{Reason: err.Error()}
 	}
-	return eq == CompareLessThan, fmt.Sprintf("should be less than %s", cmp)
+	return constraint(eq == CompareLessThan, fmt.Sprintf("should be less than %s", cmp))
 }
 
-func StdLte(v interface{}, cmp string) (bool, string) {
+func StdLte(v interface{}, cmp string) (bool, error) {
 	eq, err := compare(v, cmp)
 	if err != nil {
-		return false, err.Error()
+		return false, &UsageError{Reason: err.Error()}
 	}
-	return (CompareEqual|CompareLessThan)&eq > 0, fmt.Sprintf("should be less or equal to %s", cmp)
+	return constraint((CompareEqual|CompareLessThan)&eq > 0, fmt.Sprintf("should be less or equal to %s", cmp))
 }
 
-func StdRange(v interface{}, low, high string) (bool, string) {
+func StdRange(v interface{}, low, high string) (bool, error) {
 	eq, err := compare(v, low)
 	if err != nil {
-		return false, err.Error()
+		return false, &UsageError{Reason: err.Error()}
 	}
 	if (CompareEqual|CompareGreaterThan)&eq > 0 {
 		eq, err = compare(v, high)
 		if err != nil {
-			return false, err.Error()
+			return false, &UsageError{Reason: err.Error()}
 		}
 		if (CompareEqual|CompareLessThan)&eq > 0 {
-			return true, ""
+			return true, nil
 		}
 	}
-	return false, fmt.Sprintf("should be in the range [%s, %s]", low, high)
+	return false, fmt.Errorf("should be in the range [%s, %s]", low, high)
 }
 
-func StdEnum(v interface{}, opts ...string) (bool, string) {
+func StdEnum(v interface{}, opts ...string) (bool, error) {
 	for _, opt := range opts {
 		eq, err := compare(v, opt)
 		if err != nil {
-			return false, err.Error()
+			return false, &UsageError{Reason: err.Error()}
 		}
 		if CompareEqual&eq > 0 {
-			return true, ""
+			return true, nil
 		}
 	}
-	return false, fmt.Sprintf("should be in range %+v", opts)
+	return false, fmt.Errorf("should be in range %+v", opts)
+}
+
+func StdLen(v interface{}, maxlen int) (bool, error) {
+	reason := fmt.Sprintf("length must be exactly %d", maxlen)
+	if s, ok := v.(string); ok {
+		return constraint(len(s) == maxlen, reason)
+	} else if s, ok := v.(stringer); ok {
+		return constraint(len(s.String()) == maxlen, reason)
+	}
+	return false, usageErrorf("unexpected string type: %T", v)
+}
+
+func StdMaxLen(v interface{}, maxlen int) (bool, error) {
+	reason := fmt.Sprintf("length must be up to %d", maxlen)
+	if s, ok := v.(string); ok {
+		return constraint(len(s) <= maxlen, reason)
+	} else if s, ok := v.(stringer); ok {
+		return constraint(len(s.String()) <= maxlen, reason)
+	}
+	return false, usageErrorf("unexpected string type: %T", v)
+}
+
+func StdMinLen(v interface{}, minlen int) (bool, error) {
+	reason := fmt.Sprintf("length must be at least %d", minlen)
+	if s, ok := v.(string); ok {
+		return constraint(len(s) >= minlen, reason)
+	} else if s, ok := v.(stringer); ok {
+		return constraint(len(s.String()) >= minlen, reason)
+	}
+	return false, usageErrorf("unexpected string type: %T", v)
 }
 
-func StdLen(v interface{}, maxlen int) (bool, string) {
-	err := fmt.Sprintf("length must be exactly %d", maxlen)
+// StdMaxRunes is maxlen counting the characters rather than the bytes.
+func StdMaxRunes(v interface{}, max int) (bool, error) {
+	reason := fmt.Sprintf("length must be up to %d characters", max)
 	if s, ok := v.(string); ok {
-		return len(s) == maxlen, err
+		return constraint(utf8.RuneCountInString(s) <= max, reason)
 	} else if s, ok := v.(stringer); ok {
-		return len(s.String()) == maxlen, err
+		return constraint(utf8.RuneCountInString(s.String()) <= max, reason)
 	}
-	return false, fmt.Sprintf("unexpected string type: %t", v)
+	return false, usageErrorf("unexpected string type: %T", v)
 }
 
-func StdMaxLen(v interface{}, maxlen int) (bool, string) {
-	err := fmt.Sprintf("length must be up to %d", maxlen)
+// StdMinRunes is minlen counting the characters rather than the bytes.
+func StdMinRunes(v interface{}, min int) (bool, error) {
+	reason := fmt.Sprintf("length must be at least %d characters", min)
 	if s, ok := v.(string); ok {
-		return len(s) <= maxlen, err
+		return constraint(utf8.RuneCountInString(s) >= min, reason)
 	} else if s, ok := v.(stringer); ok {
-		return len(s.String()) <= maxlen, err
+		return constraint(utf8.RuneCountInString(s.String()) >= min, reason)
+	}
+	return false, usageErrorf("unexpected string type: %T", v)
+}
+
+func StdMinItems(v interface{}, min int) (bool, error) {
+	rv := reflect.ValueOf(v)
+	if !isCollection(rv, true) {
+		return false, usageErrorf("unexpected collection type: %T", v)
+	}
+	return constraint(rv.Len() >= min, fmt.Sprintf("should contain at least %d items", min))
+}
+
+func StdMaxItems(v interface{}, max int) (bool, error) {
+	rv := reflect.ValueOf(v)
+	if !isCollection(rv, true) {
+		return false, usageErrorf("unexpected collection type: %T", v)
+	}
+	return constraint(rv.Len() <= max, fmt.Sprintf("should contain at most %d items", max))
+}
+
+func StdUnique(v interface{}, field ...string) (bool, error) {
+	rv := reflect.ValueOf(v)
+	if !isCollection(rv, false) {
+		return false, usageErrorf("unexpected collection type: %T", v)
+	}
+	if len(field) > 1 {
+		return false, usageErrorf("unique accepts at most 1 field name, %d given", len(field))
+	}
+	keys := make([]reflect.Value, 0, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		key := rv.Index(i)
+		if len(field) > 0 {
+			fv, err := fieldByName(rv.Index(i), field[0])
+			if err != nil {
+				return false, usageErrorf("element at index %d: %s", i, err)
+			}
+			if !fv.CanInterface() {
+				return false, usageErrorf("element at index %d: field %q is not exported", i, field[0])
+			}
+			key = fv
+		}
+		for j, prev := range keys {
+			eq, err := compareValues(key, prev)
+			if err != nil {
+				return false, usageErrorf("element at index %d: %s", i, err)
+			}
+			if eq == CompareEqual {
+				err := elementErrorf(i, "element at index %d duplicates element at index %d", i, j)
+				err.Other = j
+				return false, err
+			}
+		}
+		keys = append(keys, key)
+	}
+	return true, nil
+}
+
+func StdContains(v interface{}, x string) (bool, error) {
+	rv := reflect.ValueOf(v)
+	if !isCollection(rv, false) {
+		return false, usageErrorf("unexpected collection type: %T", v)
+	}
+	for i := 0; i < rv.Len(); i++ {
+		eq, err := compare(rv.Index(i).Interface(), x)
+		if err != nil {
+			return false, usageErrorf("element at index %d: %s", i, err)
+		}
+		if eq == CompareEqual {
+			return true, nil
+		}
+	}
+	return false, fmt.Errorf("should contain %s", x)
+}
+
+func StdSubset(v interface{}, opts ...string) (bool, error) {
+	rv := reflect.ValueOf(v)
+	if !isCollection(rv, false) {
+		return false, usageErrorf("unexpected collection type: %T", v)
+	}
+Elems:
+	for i := 0; i < rv.Len(); i++ {
+		for _, opt := range opts {
+			eq, err := compare(rv.Index(i).Interface(), opt)
+			if err != nil {
+				return false, usageErrorf("element at index %d: %s", i, err)
+			}
+			if eq == CompareEqual {
+				continue Elems
+			}
+		}
+		return false, elementErrorf(i, "element at index %d should be in range %+v", i, opts)
+	}
+	return true, nil
+}
+
+func StdSorted(v interface{}, order ...string) (bool, error) {
+	rv := reflect.ValueOf(v)
+	if !isCollection(rv, false) {
+		return false, usageErrorf("unexpected collection type: %T", v)
+	}
+	dir := "asc"
+	if len(order) > 0 {
+		dir = order[0]
+	}
+	var want Equality
+	switch {
+	case len(order) > 1:
+		return false, usageErrorf("sorted accepts at most 1 argument, %d given", len(order))
+	case dir == "asc":
+		want = CompareEqual | CompareLessThan
+	case dir == "desc":
+		want = CompareEqual | CompareGreaterThan
+	default:
+		return false, usageErrorf("unexpected sort order: %q, want: asc or desc", dir)
+	}
+	for i := 1; i < rv.Len(); i++ {
+		eq, err := compareValues(rv.Index(i-1), rv.Index(i))
+		if err != nil {
+			return false, usageErrorf("element at index %d: %s", i, err)
+		}
+		if want&eq == 0 {
+			return false, elementErrorf(i, "should be sorted in %s order, element at index %d is out of order", dir, i)
+		}
+	}
+	return true, nil
+}
+
+func StdNoNil(v interface{}) (bool, error) {
+	rv := reflect.ValueOf(v)
+	if !isCollection(rv, true) {
+		return false, usageErrorf("unexpected collection type: %T", v)
+	}
+	if rv.Kind() == reflect.Map {
+		iter := rv.MapRange()
+		for iter.Next() {
+			if isNil(iter.Value()) {
+				err := elementErrorf(-1, "element with key %v should not be nil", iter.Key().Interface())
+				err.Key = iter.Key().Interface()
+				return false, err
+			}
+		}
+		return true, nil
+	}
+	for i := 0; i < rv.Len(); i++ {
+		if isNil(rv.Index(i)) {
+			return false, elementErrorf(i, "element at index %d should not be nil", i)
+		}
+	}
+	return true, nil
+}
+
+func StdEqField(ctx context.Context, v interface{}, field ...string) (bool, error) {
+	return compareField(ctx, v, field, CompareEqual, "should be equal to %s")
+}
+
+func StdNeField(ctx context.Context, v interface{}, field ...string) (bool, error) {
+	return compareField(ctx, v, field, CompareLessThan|CompareGreaterThan, "should not be equal to %s")
+}
+
+func StdGtField(ctx context.Context, v interface{}, field ...string) (bool, error) {
+	return compareField(ctx, v, field, CompareGreaterThan, "should be greater than %s")
+}
+
+func StdGteField(ctx context.Context, v interface{}, field ...string) (bool, error) {
+	return compareField(ctx, v, field, CompareEqual|CompareGreaterThan, "should be greater or equal to %s")
+}
+
+func StdLtField(ctx context.Context, v interface{}, field ...string) (bool, error) {
+	return compareField(ctx, v, field, CompareLessThan, "should be less than %s")
+}
+
+func StdLteField(ctx context.Context, v interface{}, field ...string) (bool, error) {
+	return compareField(ctx, v, field, CompareEqual|CompareLessThan, "should be less or equal to %s")
+}
+
+func compareField(ctx context.Context, v interface{}, field []string, want Equality, reason string) (bool, error) {
+	var other interface{}
+	var name string
+	switch len(field) {
+	case 0:
+		if other = ctx.Value(otherValueKey{}); other == nil {
+			return false, &UsageError{Reason: "no value to compare with"}
+		}
+		name = "the compared value"
+	case 1:
+		parent, ok := ctx.Value(structKey{}).(reflect.Value)
+		if !ok {
+			return false, usageErrorf("no struct to look up field %q in", field[0])
+		}
+		fv, err := fieldByName(parent, field[0])
+		if err != nil {
+			return false, &UsageError{Reason: err.Error()}
+		}
+		if !fv.CanInterface() {
+			return false, usageErrorf("field %q is not exported", field[0])
+		}
+		other = fv.Interface()
+		name = "field " + field[0]
+	default:
+		return false, usageErrorf("accepts at most 1 field name, %d given", len(field))
+	}
+	var eq Equality
+	var err error
+	if rv, ov := reflect.ValueOf(v), reflect.ValueOf(other); rv.IsValid() && ov.IsValid() && rv.Type() == ov.Type() {
+		eq, err = compareValues(rv, ov)
+	} else {
+		eq, err = compare(v, elemString(other))
+	}
+	if err != nil {
+		return false, &UsageError{Reason: err.Error()}
+	}
+	return constraint(want&eq > 0, fmt.Sprintf(reason, name))
+}
+
+// constraint returns the reason of a failed constraint as an error.
+func constraint(ok bool, reason string) (bool, error) {
+	if ok {
+		return true, nil
 	}
-	return false, fmt.Sprintf("unexpected string type: %t", v)
+	return false, errors.New(reason)
 }
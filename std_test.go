@@ -1,8 +1,10 @@
 package validator
 
 import (
+	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -123,6 +125,22 @@ func TestStdEnum(t *testing.T) {
 	}
 }
 
+func TestStdCompare_NamedTypes(t *testing.T) {
+	type Status string
+	type TestStruct struct {
+		Status  Status        `validate:"enum(active, blocked)"`
+		Timeout time.Duration `validate:"gt(0), lte(60000000000)"`
+	}
+
+	assert.NoError(t, Validate(TestStruct{Status: "active", Timeout: time.Second}))
+
+	err := Validate(TestStruct{Status: "gone", Timeout: time.Second})
+	assert.EqualError(t, err, "Validation failed for field \"Status\": should be in range [active blocked]")
+
+	err = Validate(TestStruct{Status: "active", Timeout: time.Hour})
+	assert.EqualError(t, err, "Validation failed for field \"Timeout\": should be less or equal to 60000000000")
+}
+
 func TestStdLen(t *testing.T) {
 	type TestStruct struct {
 		Str5 string `validate:"len(5)"`
@@ -172,3 +190,355 @@ func TestStdMaxLen(t *testing.T) {
 		assert.Equal(t, "Validation failed for field \"Str5\": length must be up to 5", err.Error())
 	}
 }
+
+func TestStdRunes(t *testing.T) {
+	type TestStruct struct {
+		Name string `validate:"minrunes(2), maxrunes(3)"`
+	}
+
+	assert.NoError(t, Validate(TestStruct{Name: "héé"}))
+	assert.NoError(t, Validate(TestStruct{Name: "日本"}))
+	assert.EqualError(t, Validate(TestStruct{Name: "é"}), "Validation failed for field \"Name\": length must be at least 2 characters")
+	assert.EqualError(t, Validate(TestStruct{Name: "héhé"}), "Validation failed for field \"Name\": length must be up to 3 characters")
+}
+
+func TestStdMinLen(t *testing.T) {
+	type TestStruct struct {
+		Str3 string `validate:"minlen(3)"`
+	}
+
+	var err error
+	valid := []string{"hey", "hello"}
+	invalid := []string{"", "hi"}
+
+	for _, v := range valid {
+		var ts TestStruct
+		ts.Str3 = v
+		err = Validate(ts)
+		assert.NoError(t, err)
+	}
+
+	for _, v := range invalid {
+		var ts TestStruct
+		ts.Str3 = v
+		err = Validate(ts)
+		assert.Error(t, err)
+		assert.Equal(t, "Validation failed for field \"Str3\": length must be at least 3", err.Error())
+	}
+}
+
+func TestStdMinMaxItems(t *testing.T) {
+	type TestStruct struct {
+		Tags  []string       `validate:"minitems(1), maxitems(3)"`
+		Attrs map[string]int `validate:"maxitems(1)"`
+	}
+
+	tests := []struct {
+		name    string
+		input   TestStruct
+		wantErr string
+	}{
+		{
+			name:  "within bounds",
+			input: TestStruct{Tags: []string{"foo", "bar"}, Attrs: map[string]int{"foo": 1}},
+		},
+		{
+			name:    "too few items",
+			input:   TestStruct{Tags: []string{}},
+			wantErr: "Validation failed for field \"Tags\": should contain at least 1 items",
+		},
+		{
+			name:    "too many items",
+			input:   TestStruct{Tags: []string{"foo", "bar", "baz", "boo"}},
+			wantErr: "Validation failed for field \"Tags\": should contain at most 3 items",
+		},
+		{
+			name:    "too many map items",
+			input:   TestStruct{Tags: []string{"foo"}, Attrs: map[string]int{"foo": 1, "bar": 2}},
+			wantErr: "Validation failed for field \"Attrs\": should contain at most 1 items",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.input)
+			if tt.wantErr != "" {
+				assert.Error(t, err)
+				assert.Equal(t, tt.wantErr, err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestStdUnique(t *testing.T) {
+	type Item struct {
+		Id   int
+		Name string
+	}
+	type TestStruct struct {
+		Ids   []int   `validate:"unique"`
+		Items []*Item `validate:"unique(Id)"`
+	}
+
+	var err error
+
+	ts := TestStruct{
+		Ids:   []int{1, 2, 3},
+		Items: []*Item{{Id: 1, Name: "foo"}, {Id: 2, Name: "foo"}},
+	}
+	err = Validate(ts)
+	assert.NoError(t, err)
+
+	ts.Ids = []int{1, 2, 1}
+	err = Validate(ts)
+	assert.Error(t, err)
+	assert.Equal(t, "Validation failed for field \"Ids\": element at index 2 duplicates element at index 0", err.Error())
+
+	ts.Ids = nil
+	ts.Items = append(ts.Items, &Item{Id: 2, Name: "bar"})
+	err = Validate(ts)
+	assert.Error(t, err)
+	assert.Equal(t, "Validation failed for field \"Items\": element at index 2 duplicates element at index 1", err.Error())
+
+	ts.Items = []*Item{{Id: 1}, nil}
+	err = Validate(ts)
+	assert.Error(t, err)
+	assert.Equal(t, "Validation failed for field \"Items\": element at index 1: nil value has no field \"Id\"", err.Error())
+}
+
+func TestStdUnique_Values(t *testing.T) {
+	type Item struct {
+		id int
+	}
+	type TestStruct struct {
+		Timeouts []time.Duration `validate:"unique"`
+		Items    []Item          `validate:"unique(id)"`
+	}
+
+	assert.NoError(t, Validate(TestStruct{Timeouts: []time.Duration{time.Second, time.Minute}}))
+	assert.EqualError(t, Validate(TestStruct{Timeouts: []time.Duration{time.Second, 1000 * time.Millisecond}}),
+		"Validation failed for field \"Timeouts\": element at index 1 duplicates element at index 0")
+	assert.EqualError(t, Validate(TestStruct{Items: []Item{{id: 1}}}),
+		"Validation failed for field \"Items\": element at index 0: field \"id\" is not exported")
+}
+
+func TestStdContains(t *testing.T) {
+	type TestStruct struct {
+		Roles []string `validate:"contains(admin)"`
+	}
+
+	var err error
+
+	err = Validate(TestStruct{Roles: []string{"user", "admin"}})
+	assert.NoError(t, err)
+
+	err = Validate(TestStruct{Roles: []string{"user"}})
+	assert.Error(t, err)
+	assert.Equal(t, "Validation failed for field \"Roles\": should contain admin", err.Error())
+}
+
+func TestStdSubset(t *testing.T) {
+	type TestStruct struct {
+		Kinds []string `validate:"subset(text, audio, video)"`
+	}
+
+	var err error
+
+	err = Validate(TestStruct{Kinds: []string{"video", "text"}})
+	assert.NoError(t, err)
+
+	err = Validate(TestStruct{Kinds: []string{"text", "image"}})
+	assert.Error(t, err)
+	assert.Equal(t, "Validation failed for field \"Kinds\": element at index 1 should be in range [text audio video]", err.Error())
+}
+
+func TestStdSorted(t *testing.T) {
+	type TestStruct struct {
+		Asc      []uint8         `validate:"sorted"`
+		Desc     []string        `validate:"sorted(desc)"`
+		Backoffs []time.Duration `validate:"sorted"`
+	}
+
+	tests := []struct {
+		name    string
+		input   TestStruct
+		wantErr string
+	}{
+		{
+			name:  "sorted",
+			input: TestStruct{Asc: []uint8{1, 1, 2, 3}, Desc: []string{"c", "b", "a"}},
+		},
+		{
+			name:    "ascending out of order",
+			input:   TestStruct{Asc: []uint8{1, 3, 2}},
+			wantErr: "Validation failed for field \"Asc\": should be sorted in asc order, element at index 2 is out of order",
+		},
+		{
+			name:    "descending out of order",
+			input:   TestStruct{Desc: []string{"a", "b"}},
+			wantErr: "Validation failed for field \"Desc\": should be sorted in desc order, element at index 1 is out of order",
+		},
+		{
+			name:  "sorted stringers",
+			input: TestStruct{Backoffs: []time.Duration{time.Nanosecond, time.Millisecond, time.Second}},
+		},
+		{
+			name:    "stringers out of order",
+			input:   TestStruct{Backoffs: []time.Duration{time.Second, time.Millisecond}},
+			wantErr: "Validation failed for field \"Backoffs\": should be sorted in asc order, element at index 1 is out of order",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.input)
+			if tt.wantErr != "" {
+				assert.Error(t, err)
+				assert.Equal(t, tt.wantErr, err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestStdNoNil(t *testing.T) {
+	type TestStruct struct {
+		Ptrs []*int          `validate:"nonil"`
+		Vals map[string]*int `validate:"nonil"`
+	}
+
+	var err error
+
+	one := 1
+	err = Validate(TestStruct{Ptrs: []*int{&one}, Vals: map[string]*int{"one": &one}})
+	assert.NoError(t, err)
+
+	err = Validate(TestStruct{Ptrs: []*int{&one, nil}})
+	assert.Error(t, err)
+	assert.Equal(t, "Validation failed for field \"Ptrs\": element at index 1 should not be nil", err.Error())
+
+	err = Validate(TestStruct{Vals: map[string]*int{"one": nil}})
+	assert.Error(t, err)
+	assert.Equal(t, "Validation failed for field \"Vals\": element with key one should not be nil", err.Error())
+}
+
+func TestStdRequired(t *testing.T) {
+	type Inner struct{}
+	type TestStruct struct {
+		Ptr   *Inner            `validate:"required"`
+		Slice []int             `validate:"required"`
+		Map   map[string]string `validate:"required"`
+		Iface interface{}       `validate:"required"`
+		Int   int               `validate:"required"`
+	}
+
+	valid := TestStruct{
+		Ptr:   &Inner{},
+		Slice: []int{},
+		Map:   map[string]string{},
+		Iface: &Inner{},
+		Int:   1,
+	}
+
+	err := Validate(valid)
+	assert.NoError(t, err)
+
+	for _, field := range []string{"Ptr", "Slice", "Map", "Iface", "Int"} {
+		ts := valid
+		switch field {
+		case "Ptr":
+			ts.Ptr = nil
+		case "Slice":
+			ts.Slice = nil
+		case "Map":
+			ts.Map = nil
+		case "Iface":
+			ts.Iface = nil
+		case "Int":
+			ts.Int = 0
+		}
+		err := Validate(ts)
+		assert.Error(t, err)
+		assert.Equal(t, fmt.Sprintf("Validation failed for field %q: is required", field), err.Error())
+	}
+}
+
+func TestStdRequired_InterfaceField(t *testing.T) {
+	type TestStruct struct {
+		Iface interface{}  `validate:"required"`
+		Str   fmt.Stringer `validate:"required"`
+	}
+
+	// an interface holding a zero value is present
+	err := Validate(TestStruct{Iface: 0, Str: time.Duration(0)})
+	assert.NoError(t, err)
+
+	err = Validate(TestStruct{Iface: 0})
+	assert.EqualError(t, err, `Validation failed for field "Str": is required`)
+
+	// a standalone value has no interface to check
+	assert.EqualError(t, Var(0, "required"), "Validation failed: is required")
+}
+
+func TestStd_UsageErrors(t *testing.T) {
+	type TestStruct struct {
+		Len   int    `validate:"maxlen(3)"`
+		Gt    int    `validate:"gt(abc)"`
+		Items int    `validate:"maxitems(2)"`
+		Msg   int    `validate:"minlen(3)" validate_msg:"too short"`
+		Order []int  `validate:"sorted(up)"`
+		Name  string `validate:"maxlen(3)" validate_msg:"too long"`
+	}
+
+	tests := []struct {
+		name    string
+		input   TestStruct
+		fields  string
+		wantErr string
+	}{
+		{
+			name:    "unsupported type",
+			fields:  "Len",
+			wantErr: `Validation failed for field "Len": unexpected string type: int`,
+		},
+		{
+			name:    "malformed argument",
+			fields:  "Gt",
+			wantErr: `Validation failed for field "Gt": strconv.ParseInt: parsing "abc": invalid syntax`,
+		},
+		{
+			name:    "unsupported collection",
+			fields:  "Items",
+			wantErr: `Validation failed for field "Items": unexpected collection type: int`,
+		},
+		{
+			name:    "message tag does not hide usage errors",
+			fields:  "Msg",
+			wantErr: `Validation failed for field "Msg": unexpected string type: int`,
+		},
+		{
+			name:    "unexpected argument",
+			input:   TestStruct{Order: []int{1}},
+			fields:  "Order",
+			wantErr: `Validation failed for field "Order": unexpected sort order: "up", want: asc or desc`,
+		},
+		{
+			name:    "message tag applies to failed constraints",
+			input:   TestStruct{Name: "abcd"},
+			fields:  "Name",
+			wantErr: `Validation failed for field "Name": too long`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.input, WithFields(tt.fields))
+			assert.EqualError(t, err, tt.wantErr)
+			var usage *UsageError
+			assert.Equal(t, tt.fields != "Name", errors.As(err, &usage))
+		})
+	}
+}
@@ -35,16 +35,21 @@ func convArgV(types []reflect.Type, args []interface{}, isVariadic bool) ([]refl
 		var val reflect.Value
 		var err error
 		switch reflect.ValueOf(arg).Kind() {
+		case reflect.Invalid:
+			val, err = convNil(types[i])
 		case reflect.String:
 			var s string
 			if strngr, ok := arg.(stringer); ok {
 				s = strngr.String()
 			} else {
-				s = arg.(string)
+				s = reflect.ValueOf(arg).String()
 			}
 			val, err = convStringVal(s, kind)
+			if err == nil {
+				val, err = convType(val, types[i])
+			}
 		default:
-			val, err = convDirectCast(arg, kind)
+			val, err = convType(reflect.ValueOf(arg), types[i])
 		}
 		if err != nil {
 			return nil, err
@@ -55,45 +60,22 @@ func convArgV(types []reflect.Type, args []interface{}, isVariadic bool) ([]refl
 	return argV, nil
 }
 
-func convDirectCast(arg interface{}, kind reflect.Kind) (reflect.Value, error) {
-	var val reflect.Value
-	switch kind {
-	case reflect.Interface:
-		val = reflect.ValueOf(arg)
-	case reflect.String:
-		val = reflect.ValueOf(arg.(string))
-	case reflect.Int:
-		val = reflect.ValueOf(arg.(int))
-	case reflect.Int8:
-		val = reflect.ValueOf(arg.(int8))
-	case reflect.Int16:
-		val = reflect.ValueOf(arg.(int16))
-	case reflect.Int32:
-		val = reflect.ValueOf(arg.(int32))
-	case reflect.Int64:
-		val = reflect.ValueOf(arg.(int64))
-	case reflect.Uint:
-		val = reflect.ValueOf(arg.(uint))
-	case reflect.Uint8:
-		val = reflect.ValueOf(arg.(uint8))
-	case reflect.Uint16:
-		val = reflect.ValueOf(arg.(uint16))
-	case reflect.Uint32:
-		val = reflect.ValueOf(arg.(uint32))
-	case reflect.Uint64:
-		val = reflect.ValueOf(arg.(uint64))
-	case reflect.Uintptr:
-		val = reflect.ValueOf(arg.(uintptr))
-	case reflect.Float32:
-		val = reflect.ValueOf(arg.(float32))
-	case reflect.Float64:
-		val = reflect.ValueOf(arg.(float64))
-	case reflect.Bool:
-		val = reflect.ValueOf(arg.(bool))
-	default:
-		return val, fmt.Errorf("unsupported kind: %v", kind)
+func convNil(typ reflect.Type) (reflect.Value, error) {
+	switch typ.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+		return reflect.Zero(typ), nil
 	}
-	return val, nil
+	return reflect.Value{}, fmt.Errorf("cannot use nil as %v", typ)
+}
+
+func convType(val reflect.Value, typ reflect.Type) (reflect.Value, error) {
+	if val.Type().AssignableTo(typ) {
+		return val, nil
+	}
+	if val.Kind() == typ.Kind() && val.Type().ConvertibleTo(typ) {
+		return val.Convert(typ), nil
+	}
+	return val, fmt.Errorf("cannot use %v as %v", val.Type(), typ)
 }
 
 func convStringVal(arg string, kind reflect.Kind) (reflect.Value, error) {
@@ -171,7 +153,7 @@ func convStringVal(arg string, kind reflect.Kind) (reflect.Value, error) {
 		if f, err := strconv.ParseFloat(arg, 32); err != nil {
 			return val, err
 		} else {
-			val = reflect.ValueOf(f)
+			val = reflect.ValueOf(float32(f))
 		}
 	case reflect.Float64:
 		if f, err := strconv.ParseFloat(arg, 64); err != nil {
@@ -198,6 +180,11 @@ func compare(v interface{}, cmp string) (Equality, error) {
 	if err != nil {
 		return 0, err
 	}
+	if rv.Type() != cmpv.Type() {
+		// a named type like time.Duration is compared by its underlying value
+		rv = rv.Convert(cmpv.Type())
+		v = rv.Interface()
+	}
 	switch rv.Kind() {
 	case reflect.Bool:
 		bv := v.(bool)
@@ -331,6 +318,28 @@ func compare(v interface{}, cmp string) (Equality, error) {
 				eq = CompareGreaterThan
 			}
 		}
+	case reflect.Float32:
+		fv := v.(float32)
+		if rv.Interface() == cmpv.Interface() {
+			eq = CompareEqual
+		} else {
+			if fv < float32(cmpv.Float()) {
+				eq = CompareLessThan
+			} else {
+				eq = CompareGreaterThan
+			}
+		}
+	case reflect.Float64:
+		fv := v.(float64)
+		if rv.Interface() == cmpv.Interface() {
+			eq = CompareEqual
+		} else {
+			if fv < cmpv.Float() {
+				eq = CompareLessThan
+			} else {
+				eq = CompareGreaterThan
+			}
+		}
 	case reflect.String:
 		sv := v.(string)
 		if rv.Interface() == cmpv.Interface() {
@@ -349,6 +358,105 @@ func compare(v interface{}, cmp string) (Equality, error) {
 	return eq, nil
 }
 
+// compareValues compares a to b by their underlying values, the dynamic
+// values for interfaces. Unlike compare it does not go through the string
+// form of the values, so a named type with a String method, like
+// time.Duration, is compared by its number.
+func compareValues(a, b reflect.Value) (Equality, error) {
+	for a.Kind() == reflect.Interface && !a.IsNil() {
+		a = a.Elem()
+	}
+	for b.Kind() == reflect.Interface && !b.IsNil() {
+		b = b.Elem()
+	}
+	if !a.IsValid() || !b.IsValid() || a.Type() != b.Type() {
+		return 0, fmt.Errorf("cannot compare %s to %s", typeString(a), typeString(b))
+	}
+	var less, equal bool
+	switch a.Kind() {
+	case reflect.Bool:
+		// true is greater than false
+		less, equal = !a.Bool() && b.Bool(), a.Bool() == b.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		less, equal = a.Int() < b.Int(), a.Int() == b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		less, equal = a.Uint() < b.Uint(), a.Uint() == b.Uint()
+	case reflect.Float32, reflect.Float64:
+		less, equal = a.Float() < b.Float(), a.Float() == b.Float()
+	case reflect.String:
+		less, equal = a.String() < b.String(), a.String() == b.String()
+	default:
+		return 0, fmt.Errorf("kind %v is not comparable", a.Kind())
+	}
+	switch {
+	case equal:
+		return CompareEqual, nil
+	case less:
+		return CompareLessThan, nil
+	}
+	return CompareGreaterThan, nil
+}
+
+func typeString(v reflect.Value) string {
+	if !v.IsValid() {
+		return "nil"
+	}
+	return v.Type().String()
+}
+
 func duplicateValidatorDefErr(handle string) error {
 	return fmt.Errorf("Duplicate validator definition: %s", handle)
 }
+
+func isCollection(rv reflect.Value, allowMap bool) bool {
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		return true
+	case reflect.Map:
+		return allowMap
+	}
+	return false
+}
+
+func isNil(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+		return rv.IsNil()
+	}
+	return false
+}
+
+func isZero(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	zv := reflect.Zero(rv.Type())
+	return reflect.DeepEqual(rv.Interface(), zv.Interface())
+}
+
+func elemString(v interface{}) string {
+	if s, ok := v.(stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprint(v)
+}
+
+func fieldByName(rv reflect.Value, name string) (reflect.Value, error) {
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return rv, fmt.Errorf("nil value has no field %q", name)
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return rv, fmt.Errorf("%v is not a struct", rv.Type())
+	}
+	fv := rv.FieldByName(name)
+	if !fv.IsValid() {
+		return fv, fmt.Errorf("%v has no field %q", rv.Type(), name)
+	}
+	return fv, nil
+}
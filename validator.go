@@ -1,9 +1,12 @@
 package validator
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
+	"time"
 )
 
 type Equality uint8
@@ -21,65 +24,144 @@ const (
 
 const (
 	ValidateTagName = "validate"
+	MessageTagName  = "validate_msg"
 )
 
-var validators map[string]func(interface{}, ...interface{}) (bool, error)
+var validators map[string]func(context.Context, interface{}, ...interface{}) (bool, error)
+
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
 
 func init() {
-	validators = make(map[string]func(interface{}, ...interface{}) (bool, error))
-
-	Register("empty", StdEmpty)
-	Register("enum", StdEnum)
-	Register("eq", StdEq)
-	Register("gt", StdGt)
-	Register("gte", StdGte)
-	Register("len", StdLen)
-	Register("lt", StdLt)
-	Register("lte", StdLte)
-	Register("maxlen", StdMaxLen)
-	Register("ne", StdNe)
-	Register("none", StdNone)
-	Register("nonempty", StdNonEmpty)
-	Register("optional", StdOptional)
-	Register("range", StdRange)
+	validators = make(map[string]func(context.Context, interface{}, ...interface{}) (bool, error))
+
+	registerStd("contains", StdContains)
+	registerStd("empty", StdEmpty)
+	registerStd("enum", StdEnum)
+	registerStd("eq", StdEq)
+	registerStd("eqfield", StdEqField)
+	registerStd("gt", StdGt)
+	registerStd("gte", StdGte)
+	registerStd("gtefield", StdGteField)
+	registerStd("gtfield", StdGtField)
+	registerStd("len", StdLen)
+	registerStd("lt", StdLt)
+	registerStd("lte", StdLte)
+	registerStd("ltefield", StdLteField)
+	registerStd("ltfield", StdLtField)
+	registerStd("maxitems", StdMaxItems)
+	registerStd("maxlen", StdMaxLen)
+	registerStd("maxrunes", StdMaxRunes)
+	registerStd("minitems", StdMinItems)
+	registerStd("minlen", StdMinLen)
+	registerStd("minrunes", StdMinRunes)
+	registerStd("ne", StdNe)
+	registerStd("nefield", StdNeField)
+	registerStd("none", StdNone)
+	registerStd("nonempty", StdNonEmpty)
+	registerStd("nonil", StdNoNil)
+	registerStd("optional", StdOptional)
+	registerStd("range", StdRange)
+	registerStd("required", StdRequired)
+	registerStd("sorted", StdSorted)
+	registerStd("subset", StdSubset)
+	registerStd("unique", StdUnique)
 }
 
+// stdHandles are the validators bundled with the package, their reasons
+// never include the validated value.
+var stdHandles = map[string]bool{TypeCheckHandle: true}
+
+func registerStd(handle string, check interface{}) {
+	if err := Register(handle, check); err != nil {
+		panic(err)
+	}
+	stdHandles[handle] = true
+}
+
+type resultShape uint8
+
+const (
+	resultBool resultShape = iota
+	resultBoolReason
+	resultBoolReasonCont
+	resultError
+	resultBoolError
+)
+
+var (
+	boolType   = reflect.TypeOf(true)
+	stringType = reflect.TypeOf("")
+	errorType  = reflect.TypeOf((*error)(nil)).Elem()
+)
+
 func Register(handle string, check interface{}) error {
 	if _, ok := validators[handle]; ok {
 		return duplicateValidatorDefErr(handle)
 	}
+	if _, ok := aliases[handle]; ok {
+		return duplicateValidatorDefErr(handle)
+	}
+	if _, ok := normalizers[handle]; ok {
+		return duplicateValidatorDefErr(handle)
+	}
 	checkV := reflect.ValueOf(check)
 	checkT := reflect.TypeOf(check)
+	if checkT == nil || checkT.Kind() != reflect.Func {
+		return fmt.Errorf("Validator %q should be a func, %T given", handle, check)
+	}
+	shape, err := getResultShape(checkT)
+	if err != nil {
+		return fmt.Errorf("Validator %q has unsupported signature %v: %s", handle, checkT, err)
+	}
 	isVariadic := checkT.IsVariadic()
+	withCtx := checkT.NumIn() > 0 && checkT.In(0) == contextType
 	types := make([]reflect.Type, 0, checkT.NumIn())
 	for i := 0; i < checkT.NumIn(); i++ {
+		if i == 0 && withCtx {
+			continue
+		}
 		types = append(types, checkT.In(i))
 	}
 
-	validators[handle] = func(v interface{}, args ...interface{}) (bool, error) {
+	validators[handle] = func(ctx context.Context, v interface{}, args ...interface{}) (bool, error) {
 		if len(types) > 0 {
 			args = append([]interface{}{v}, args...)
 		}
 		argV, err := convArgV(types, args, isVariadic)
 		if err != nil {
-			return Break, fmt.Errorf("argument conversion failed: %s", err)
+			return Break, usageErrorf("argument conversion failed: %s", err)
+		}
+		if withCtx {
+			argV = append([]reflect.Value{reflect.ValueOf(ctx)}, argV...)
 		}
 		resV := checkV.Call(argV)
 
-		match := resV[0].Bool()
+		match := true
 		reason := "constraint mismatch"
 		cont := Continue
-		if len(resV) > 1 {
-			reason = resV[1].String()
+		var cause error
+		switch shape {
+		case resultBool:
+			match = resV[0].Bool()
+		case resultBoolReason:
+			match, reason = resV[0].Bool(), resV[1].String()
+		case resultBoolReasonCont:
+			match, reason, cont = resV[0].Bool(), resV[1].String(), resV[2].Bool()
+		case resultError:
+			cause, _ = resV[0].Interface().(error)
+		case resultBoolError:
+			match = resV[0].Bool()
+			cause, _ = resV[1].Interface().(error)
+		}
+		var usage *UsageError
+		if errors.As(cause, &usage) {
+			return Break, cause
 		}
-		if len(resV) > 2 {
-			cont = resV[2].Bool()
+		if cause != nil {
+			return Break, &mismatchError{reason: cause.Error(), err: cause}
 		}
 		if !match {
-			if len(resV) > 1 {
-				reason = resV[1].String()
-			}
-			return Break, errors.New(reason)
+			return Break, &mismatchError{reason: reason}
 		}
 		return cont, nil
 	}
@@ -87,61 +169,471 @@ func Register(handle string, check interface{}) error {
 	return nil
 }
 
-func Validate(datum interface{}) error {
-	datumT := reflect.TypeOf(datum)
+func getResultShape(checkT reflect.Type) (resultShape, error) {
+	out := make([]reflect.Type, 0, checkT.NumOut())
+	for i := 0; i < checkT.NumOut(); i++ {
+		out = append(out, checkT.Out(i))
+	}
+	switch {
+	case len(out) == 1 && out[0] == boolType:
+		return resultBool, nil
+	case len(out) == 1 && out[0] == errorType:
+		return resultError, nil
+	case len(out) == 2 && out[0] == boolType && out[1] == stringType:
+		return resultBoolReason, nil
+	case len(out) == 2 && out[0] == boolType && out[1] == errorType:
+		return resultBoolError, nil
+	case len(out) == 3 && out[0] == boolType && out[1] == stringType && out[2] == boolType:
+		return resultBoolReasonCont, nil
+	}
+	return 0, errors.New("want one of: bool, error, (bool, string), (bool, error), (bool, string, bool)")
+}
+
+func Validate(datum interface{}, opts ...Option) error {
+	return ValidateCtx(context.Background(), datum, opts...)
+}
+
+// ValidateCtx validates datum passing ctx to the validators accepting a
+// context.Context as the first argument. The validation stops with the
+// context error as soon as ctx is done.
+func ValidateCtx(ctx context.Context, datum interface{}, opts ...Option) error {
+	s := newValidation(ctx, opts...)
+	s.start(datum)
+	return s.end(s.result(s.validate(datum)))
+}
+
+type validation struct {
+	*options
+	ctx     context.Context
+	depth   int
+	visited map[visitKey]bool
+	errs    ValidationErrors
+	pass    walkPass
+	// audit records the changes made by the normalizers and sanitizers
+	audit   bool
+	changes []Change
+	// datumType and started are set for the observer only
+	datumType reflect.Type
+	started   time.Time
+	// trace is set by Explain only
+	trace      *Trace
+	traceDepth int
+}
+
+// walkPass is what the walk over the value does to the fields.
+type walkPass uint8
+
+const (
+	passCheck walkPass = iota
+	passNormalize
+	passSanitize
+)
+
+func newValidation(ctx context.Context, opts ...Option) *validation {
+	return &validation{
+		options: newOptions(opts...),
+		ctx:     ctx,
+	}
+}
+
+// validate normalizes the value first if it is given by a pointer, then
+// checks it.
+func (s *validation) validate(datum interface{}) error {
 	datumV := reflect.ValueOf(datum)
+	if datumV.Kind() == reflect.Ptr && !datumV.IsNil() {
+		if err := s.walkPass(passNormalize, datum); err != nil {
+			return err
+		}
+	}
+	return s.walkPass(passCheck, datum)
+}
+
+func (s *validation) walkPass(pass walkPass, datum interface{}) error {
+	s.pass = pass
+	s.visited = nil
+	return s.walk(datum)
+}
 
-	for datumT.Kind() == reflect.Ptr {
-		datumT = datumT.Elem()
+func (s *validation) walk(datum interface{}) error {
+	datumV := reflect.ValueOf(datum)
+	for datumV.Kind() == reflect.Ptr {
+		if !datumV.IsNil() {
+			s.enter(datumV)
+			defer s.leave(datumV)
+		}
 		datumV = datumV.Elem()
 	}
 
-	if datumT.Kind() != reflect.Struct {
-		return fmt.Errorf("Validate accepts a struct, %#v %T given", datum, datum)
+	switch datumV.Kind() {
+	case reflect.Struct:
+		return s.validateStruct(datumV, fieldPath{}, nil)
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if isStructCollection(datumV.Type()) {
+			return s.validateElems(datumV, fieldPath{})
+		}
 	}
 
-Datum:
+	return fmt.Errorf("Validate accepts a struct or a collection of structs, %#v %T given", datum, datum)
+}
+
+func (s *validation) validateStruct(datumV reflect.Value, path fieldPath, embed *embedding) error {
+	if embed == nil {
+		s.depth++
+		defer func() { s.depth-- }()
+		if s.maxDepth > 0 && s.depth > s.maxDepth {
+			return &DepthError{Path: path.path, Pointer: path.pointer, MaxDepth: s.maxDepth}
+		}
+	}
+	datumT := datumV.Type()
+	ctx := context.WithValue(s.ctx, structKey{}, datumV)
+	if s.trace != nil {
+		st := TraceStep{Kind: TraceStruct, Path: path.path, Type: datumT}
+		if embed != nil {
+			st.Note = "embedded"
+		}
+		s.traceStep(st)
+		depth := s.traceDepth
+		s.traceDepth++
+		defer func() { s.traceDepth = depth }()
+	}
+	fieldDepth := s.traceDepth
+
 	for i := 0; i < datumT.NumField(); i++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		field := datumT.Field(i)
-		v := datumV.FieldByIndex([]int{i})
-		if tagDef, ok := field.Tag.Lookup(ValidateTagName); ok {
-			tags := parseValidateTags(tagDef)
-			for _, tag := range tags {
-				check, ok := validators[tag.Op]
-				if !ok {
-					return fmt.Errorf("Validator %q is unknown", tag.Op)
+		if field.PkgPath != "" && !field.Anonymous {
+			// unexported fields are not accessible
+			continue
+		}
+		s.traceDepth = fieldDepth
+		v := datumV.Field(i)
+		fpath, qpath := embed.fieldPaths(path, field, i)
+		promote := !s.embeddedNames && isPromoted(field)
+		check, dive := s.selectField(fpath, qpath)
+		if promote && s.mask != nil && !s.mask.exclude {
+			// promoted fields are selected on their own
+			dive = true
+		}
+		if !check && !dive {
+			s.traceSkip(fpath, "not selected")
+			continue
+		}
+		rules, err := parseGroupRules(s.lookupRules(field.Tag))
+		if err != nil {
+			return err
+		}
+		if rules.skip {
+			s.traceSkip(fpath, "skip directive")
+			continue
+		}
+		if s.trace != nil {
+			s.traceStep(TraceStep{Kind: TraceField, Path: fpath.path})
+			s.traceDepth++
+		}
+		switch {
+		case s.pass == passNormalize:
+			if check && len(rules.normalize) > 0 && v.CanSet() {
+				if err := s.normalizeField(field.Name, fpath, v, rules); err != nil {
+					return err
 				}
-				cont, err := check(v.Interface(), tag.Args...)
-				if err != nil {
-					return fmt.Errorf("Validation failed for field %q: %s", field.Name, err)
+			}
+		case s.pass == passSanitize:
+			if check && v.CanSet() {
+				if err := s.sanitizeField(field.Name, fpath, v, rules); err != nil {
+					return err
+				}
+			}
+		case check && len(rules.tags) > 0 && v.CanInterface():
+			fctx := ctx
+			if v.Kind() == reflect.Interface {
+				fctx = context.WithValue(ctx, ifaceFieldKey{}, v)
+			}
+			cont, err := s.checkTags(fctx, field.Name, fpath, field.Tag, rules, v.Interface())
+			if err != nil {
+				if err = s.report(err); err != nil {
+					return err
 				}
-				if cont == Break {
-					return nil
+				if dive && diveable(v) {
+					s.traceSkip(fpath, "failed, not descended")
 				}
+				continue
+			}
+			if cont == Break {
+				if dive && !rules.nodive && diveable(v) {
+					s.traceSkip(fpath, "chain broken, not descended")
+				}
+				continue
 			}
 		}
 
-		p := v
-
-	Deref:
-		switch p.Kind() {
-		case reflect.Struct:
-			if err := Validate(p.Interface()); err != nil {
-				return err
+		if !dive {
+			continue
+		}
+		if rules.nodive {
+			if diveable(v) {
+				s.traceSkip(fpath, "nodive directive")
 			}
-		case reflect.Ptr:
-			if p.IsNil() {
-				continue Datum
+			continue
+		}
+		if promote {
+			err = s.descendEmbedded(v, path, embed.nest(datumT, i, qpath))
+		} else {
+			err = s.descend(v, fpath)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *validation) descend(v reflect.Value, path fieldPath) error {
+	p := v
+
+Deref:
+	switch p.Kind() {
+	case reflect.Struct:
+		return s.validateStruct(p, path, nil)
+	case reflect.Ptr:
+		if p.IsNil() {
+			s.traceSkip(path, "nil pointer")
+			return nil
+		}
+		if s.enter(p) {
+			s.traceSkip(path, "cycle")
+			return nil
+		}
+		defer s.leave(p)
+		p = p.Elem()
+		goto Deref
+	case reflect.Interface:
+		if p.IsNil() {
+			s.traceSkip(path, "nil interface")
+			return nil
+		}
+		if err := s.checkDynamicType(p, path); err != nil {
+			if s.pass != passCheck {
+				// reported by the check pass, not modified meanwhile
+				return nil
 			}
-			if p.Kind() == reflect.Ptr {
-				p = p.Elem()
-				goto Deref
+			return s.report(err)
+		}
+		path = path.dynamic(p.Elem().Type())
+		if s.pass != passCheck && p.CanSet() {
+			return s.descendCopy(p.Elem(), path, p.Set)
+		}
+		p = p.Elem()
+		goto Deref
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if isStructCollection(p.Type()) {
+			return s.validateElems(p, path)
+		}
+	}
+
+	return nil
+}
+
+func (s *validation) validateElems(v reflect.Value, path fieldPath) error {
+	if (v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && v.Len() > 0 {
+		if s.enter(v) {
+			s.traceSkip(path, "cycle")
+			return nil
+		}
+		defer s.leave(v)
+	}
+	if v.Kind() == reflect.Map {
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		for _, key := range keys {
+			elem, kpath := v.MapIndex(key), path.index(key.Interface())
+			var err error
+			if s.pass != passCheck && v.CanInterface() {
+				err = s.descendCopy(elem, kpath, func(cp reflect.Value) {
+					v.SetMapIndex(key, cp)
+				})
+			} else {
+				err = s.descend(elem, kpath)
 			}
-			if err := Validate(p.Interface()); err != nil {
+			if err != nil {
 				return err
 			}
 		}
+		return nil
+	}
+	for i := 0; i < v.Len(); i++ {
+		if err := s.descend(v.Index(i), path.index(i)); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
+// descendCopy descends into a copy of v, a value held in a map or an
+// interface which can't be modified in place, and stores the copy back if a
+// normalizer or a sanitizer changed it.
+func (s *validation) descendCopy(v reflect.Value, path fieldPath, store func(reflect.Value)) error {
+	switch v.Kind() {
+	case reflect.Struct, reflect.Array, reflect.Interface:
+	default:
+		return s.descend(v, path)
+	}
+	cp := reflect.New(v.Type()).Elem()
+	cp.Set(v)
+	if err := s.descend(cp, path); err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(cp.Interface(), v.Interface()) {
+		store(cp)
+	}
 	return nil
 }
+
+// checkTags runs the validator chains of the field. The chain of each group
+// is run on its own, a chain break skips the rest of that chain only.
+func (s *validation) checkTags(ctx context.Context, name string, path fieldPath, fieldTag reflect.StructTag, rules *ruleSet, value interface{}) (bool, error) {
+	if rules.chains == nil {
+		return s.checkChain(ctx, name, path, fieldTag, rules, rules.tags, value)
+	}
+	res := Break
+	for _, chain := range rules.chains {
+		cont, err := s.checkChain(ctx, name, path, fieldTag, rules, chain, value)
+		if err != nil {
+			return Break, err
+		}
+		if cont == Continue {
+			res = Continue
+		}
+	}
+	return res, nil
+}
+
+func (s *validation) checkChain(ctx context.Context, name string, path fieldPath, fieldTag reflect.StructTag, rules *ruleSet, chain []ValidateTag, value interface{}) (bool, error) {
+	for _, tag := range chain {
+		check, ok := validators[tag.Op]
+		if !ok {
+			err := fmt.Errorf("Validator %q is unknown", tag.Op)
+			s.traceStep(TraceStep{Kind: TraceCheck, Path: path.path, Handle: tag.Op, Args: tag.Args, Err: err})
+			return Break, err
+		}
+		var started time.Time
+		if s.observer != nil {
+			started = time.Now()
+		}
+		cont, err := check(ctx, value, tag.Args...)
+		var elapsed time.Duration
+		if s.observer != nil {
+			elapsed = time.Since(started)
+		}
+		if err != nil {
+			err = s.fieldError(name, path, fieldTag, tag, value, err, rules.sensitive)
+		}
+		if s.observer != nil {
+			s.observer.OnFieldCheck(FieldCheck{
+				Type:     s.datumType,
+				Path:     path.path,
+				Handle:   tag.Op,
+				Duration: elapsed,
+				Err:      err,
+			})
+		}
+		if s.trace != nil {
+			s.traceStep(TraceStep{
+				Kind:   TraceCheck,
+				Path:   path.path,
+				Handle: tag.Op,
+				Args:   traceArgs(value, tag.Args),
+				Err:    err,
+				Break:  err == nil && cont == Break,
+			})
+		}
+		if err != nil {
+			return Break, err
+		}
+		if cont == Break {
+			return Break, nil
+		}
+	}
+	return Continue, nil
+}
+
+func (s *validation) fieldError(name string, path fieldPath, fieldTag reflect.StructTag, tag ValidateTag, value interface{}, err error, sensitive bool) error {
+	fe := &FieldError{
+		Field:   name,
+		Path:    path.path,
+		Pointer: path.pointer,
+		Handle:  tag.Op,
+		Alias:   tag.Alias,
+		Args:    tag.Args,
+		Value:   value,
+		Reason:  err.Error(),
+		Err:     err,
+	}
+	if sensitive {
+		fe.Value = Redacted
+		fe.Reason = sensitiveReason(tag.Op, err)
+		fe.Err = &redactedError{err: err, text: fe.Reason}
+	}
+	data := MessageData{
+		Field:  fe.Field,
+		Value:  fe.Value,
+		Args:   fe.Args,
+		Reason: fe.Reason,
+		Index:  -1,
+		Other:  -1,
+	}
+	var elem *ElementError
+	if errors.As(err, &elem) {
+		data.Index, data.Key, data.Other = elem.Index, elem.Key, elem.Other
+	}
+	fe.Message = fe.Reason
+	var mismatch *mismatchError
+	if errors.As(err, &mismatch) {
+		if text, ok := fieldTag.Lookup(MessageTagName); ok {
+			msg, err := renderMessage(text, data)
+			if err != nil {
+				return fmt.Errorf("Malformed %s tag for field %q: %s", MessageTagName, name, err)
+			}
+			fe.Message = msg
+		} else if msg, ok := translate(s.options, tag.Op, data); ok {
+			fe.Message = msg
+		}
+	}
+	data.Reason = fe.Message
+	if text, ok := translate(s.options, ValidationFailedKey, data); ok {
+		fe.text = text
+	}
+	return fe
+}
+
+// report collects a failed constraint when all the errors are collected. Any
+// other error, e.g. a validator that can't be applied to the field, stops the
+// validation.
+func (s *validation) report(err error) error {
+	var fe *FieldError
+	var mismatch *mismatchError
+	if s.allErrors && errors.As(err, &fe) && errors.As(fe.Err, &mismatch) {
+		s.errs = append(s.errs, fe)
+		return nil
+	}
+	return err
+}
+
+func (s *validation) result(err error) error {
+	if err == nil && len(s.errs) > 0 {
+		return s.errs
+	}
+	return err
+}
+
+func isStructCollection(t reflect.Type) bool {
+	et := t.Elem()
+	for et.Kind() == reflect.Ptr {
+		et = et.Elem()
+	}
+	return et.Kind() == reflect.Struct || et.Kind() == reflect.Interface
+}
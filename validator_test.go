@@ -1,7 +1,10 @@
 package validator
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -104,3 +107,253 @@ func TestValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateCtx(t *testing.T) {
+	type tenantKey struct{}
+	Register("ctx_tenant", func(ctx context.Context, v string) (bool, string) {
+		tenant, _ := ctx.Value(tenantKey{}).(string)
+		return strings.HasPrefix(v, tenant+"/"), "should belong to tenant " + tenant
+	})
+
+	type TestStruct struct {
+		Resource string `validate:"nonempty, ctx_tenant"`
+	}
+
+	var err error
+
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+
+	err = ValidateCtx(ctx, TestStruct{Resource: "acme/doc"})
+	assert.NoError(t, err)
+
+	err = ValidateCtx(ctx, TestStruct{Resource: "globex/doc"})
+	assert.Error(t, err)
+	assert.Equal(t, `Validation failed for field "Resource": should belong to tenant acme`, err.Error())
+
+	err = Validate(TestStruct{Resource: "acme/doc"})
+	assert.Error(t, err)
+	assert.Equal(t, `Validation failed for field "Resource": should belong to tenant `, err.Error())
+
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	err = ValidateCtx(cctx, TestStruct{Resource: "globex/doc"})
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestRegisterSignature(t *testing.T) {
+	tests := []struct {
+		name    string
+		check   interface{}
+		wantErr bool
+	}{
+		{name: "bool", check: func(v int) bool { return true }},
+		{name: "bool and reason", check: func(v int) (bool, string) { return true, "" }},
+		{name: "bool, reason and chain control", check: func(v int) (bool, string, bool) { return true, "", Continue }},
+		{name: "error", check: func(v int) error { return nil }},
+		{name: "bool and error", check: func(v int, extra ...string) (bool, error) { return true, nil }},
+		{name: "not a func", check: 42, wantErr: true},
+		{name: "nil", check: nil, wantErr: true},
+		{name: "no results", check: func(v int) {}, wantErr: true},
+		{name: "string", check: func(v int) string { return "" }, wantErr: true},
+		{name: "reason first", check: func(v int) (string, bool) { return "", true }, wantErr: true},
+		{name: "too many results", check: func(v int) (bool, string, bool, error) { return true, "", true, nil }, wantErr: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Register(fmt.Sprintf("signature_%d", i), tt.check)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestErrorValidators(t *testing.T) {
+	errReserved := errors.New("reserved name")
+	Register("error_not_reserved", func(v string) error {
+		if v == "admin" {
+			return fmt.Errorf("%q is a %w", v, errReserved)
+		}
+		return nil
+	})
+	Register("error_short", func(v string, max int) (bool, error) {
+		return len(v) <= max, nil
+	})
+
+	type TestStruct struct {
+		Name string `validate:"error_not_reserved, error_short(8)"`
+	}
+
+	var err error
+
+	err = Validate(TestStruct{Name: "root"})
+	assert.NoError(t, err)
+
+	err = Validate(TestStruct{Name: "admin"})
+	assert.Error(t, err)
+	assert.Equal(t, `Validation failed for field "Name": "admin" is a reserved name`, err.Error())
+	assert.True(t, errors.Is(err, errReserved))
+
+	err = Validate(TestStruct{Name: "administrator"})
+	assert.Error(t, err)
+	assert.Equal(t, `Validation failed for field "Name": constraint mismatch`, err.Error())
+	assert.False(t, errors.Is(err, errReserved))
+}
+
+func TestFieldErrorPointer(t *testing.T) {
+	type Item struct {
+		Title string `json:"title,omitempty" validate:"nonempty"`
+	}
+	type TestStruct struct {
+		Items []Item `json:"items"`
+	}
+
+	err := Validate(TestStruct{Items: []Item{{Title: "foo"}, {}}})
+	assert.Error(t, err)
+	fe, ok := err.(*FieldError)
+	if assert.True(t, ok) {
+		assert.Equal(t, "Items[1].Title", fe.Path)
+		assert.Equal(t, "/items/1/title", fe.Pointer)
+	}
+}
+
+func TestRecursionControl(t *testing.T) {
+	type Inner struct {
+		Val int `validate:"gt(0)"`
+	}
+	type TestStruct struct {
+		Skipped  *Inner `validate:"-"`
+		Shallow  *Inner `validate:"nonempty, nodive"`
+		Required *Inner `validate:"required"`
+		Deep     Inner
+	}
+
+	tests := []struct {
+		name     string
+		input    TestStruct
+		wantErr  string
+		wantPath string
+	}{
+		{
+			name:  "skipped and shallow fields are not visited",
+			input: TestStruct{Skipped: &Inner{}, Shallow: &Inner{}, Required: &Inner{Val: 1}, Deep: Inner{Val: 1}},
+		},
+		{
+			name:     "nodive still checks the field tags",
+			input:    TestStruct{Required: &Inner{Val: 1}, Deep: Inner{Val: 1}},
+			wantErr:  `Validation failed for field "Shallow": should not be empty`,
+			wantPath: "Shallow",
+		},
+		{
+			name:     "required nil pointer",
+			input:    TestStruct{Shallow: &Inner{}, Deep: Inner{Val: 1}},
+			wantErr:  `Validation failed for field "Required": is required`,
+			wantPath: "Required",
+		},
+		{
+			name:     "required pointer contents are validated",
+			input:    TestStruct{Shallow: &Inner{}, Required: &Inner{}, Deep: Inner{Val: 1}},
+			wantErr:  `Validation failed for field "Val": should be greater than 0`,
+			wantPath: "Required.Val",
+		},
+		{
+			name:     "untagged struct is visited",
+			input:    TestStruct{Shallow: &Inner{}, Required: &Inner{Val: 1}},
+			wantErr:  `Validation failed for field "Val": should be greater than 0`,
+			wantPath: "Deep.Val",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.input)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Equal(t, tt.wantErr, err.Error())
+			fe, ok := err.(*FieldError)
+			if assert.True(t, ok) {
+				assert.Equal(t, tt.wantPath, fe.Path)
+			}
+		})
+	}
+}
+
+func TestChainBreak(t *testing.T) {
+	type TestStruct struct {
+		Optional string `validate:"optional, maxlen(3)"`
+		Required string `validate:"nonempty"`
+	}
+
+	err := Validate(TestStruct{})
+	assert.Error(t, err)
+	assert.Equal(t, `Validation failed for field "Required": should not be empty`, err.Error())
+}
+
+func TestAllErrors(t *testing.T) {
+	type Item struct {
+		Title string `validate:"nonempty"`
+	}
+	type TestStruct struct {
+		Name  string  `validate:"nonempty"`
+		Age   int     `validate:"gte(18)"`
+		Items []Item  `validate:"maxitems(2)"`
+		Main  *Item   `validate:"required"`
+		Extra []*Item `validate:"maxitems(1)"`
+	}
+
+	err := Validate(TestStruct{
+		Age:   17,
+		Items: []Item{{Title: "foo"}, {}},
+		Extra: []*Item{{}, {}},
+	}, WithAllErrors())
+	assert.Error(t, err)
+	errs, ok := err.(ValidationErrors)
+	if assert.True(t, ok) {
+		paths := []string{}
+		for _, fe := range errs {
+			paths = append(paths, fe.Path)
+		}
+		// Extra is not descended into once it fails
+		assert.Equal(t, []string{"Name", "Age", "Items[1].Title", "Main", "Extra"}, paths)
+	}
+	assert.Equal(t, `Validation failed for field "Name": should not be empty; `+
+		`Validation failed for field "Age": should be greater or equal to 18; `+
+		`Validation failed for field "Title": should not be empty; `+
+		`Validation failed for field "Main": is required; `+
+		`Validation failed for field "Extra": should contain at most 1 items`, err.Error())
+
+	assert.NoError(t, Validate(TestStruct{Name: "foo", Age: 18, Main: &Item{Title: "bar"}}, WithAllErrors()))
+
+	err = ValidateMap(map[string]interface{}{"a": 1}, map[string]string{"a": "gt(1)", "b": "required"}, WithAllErrors())
+	assert.Len(t, err, 2)
+}
+
+func TestAllErrors_UsageErrors(t *testing.T) {
+	type TestStruct struct {
+		Name string `validate:"nonempty"`
+		Age  int    `validate:"maxlen(3)"`
+	}
+
+	// a validator that can't be applied is not a constraint failure
+	err := Validate(TestStruct{}, WithAllErrors())
+	assert.EqualError(t, err, `Validation failed for field "Age": unexpected string type: int`)
+	var usage *UsageError
+	assert.True(t, errors.As(err, &usage))
+	_, collected := err.(ValidationErrors)
+	assert.False(t, collected)
+
+	Register("allerrors_positive", func(v int) bool { return v > 0 })
+	type Conversion struct {
+		Name  string   `validate:"nonempty"`
+		Count []string `validate:"allerrors_positive"`
+	}
+	err = Validate(Conversion{Count: []string{"1"}}, WithAllErrors())
+	assert.True(t, errors.As(err, &usage))
+	assert.Contains(t, err.Error(), "argument conversion failed")
+}
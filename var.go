@@ -0,0 +1,78 @@
+package validator
+
+import (
+	"context"
+	"reflect"
+)
+
+type structKey struct{}
+
+// ifaceFieldKey holds an interface-typed field, the validators receive its
+// dynamic value.
+type ifaceFieldKey struct{}
+
+type otherValueKey struct{}
+
+// Var validates a standalone value against a tag-style validator chain, e.g.
+//
+//	err := validator.Var(limit, "range(1, 100)")
+func Var(value interface{}, tag string, opts ...Option) error {
+	s := newValidation(context.Background(), opts...)
+	s.start(value)
+	return s.end(s.result(s.validateVar(value, tag)))
+}
+
+// VarWithValue validates value the same way Var does, making other available
+// to the cross-field validators like eqfield, which compare against it when
+// called without a field name.
+func VarWithValue(value, other interface{}, tag string, opts ...Option) error {
+	ctx := context.WithValue(context.Background(), otherValueKey{}, other)
+	s := newValidation(ctx, opts...)
+	s.start(value)
+	return s.end(s.result(s.validateVar(value, tag)))
+}
+
+func (s *validation) validateVar(value interface{}, tagDef string) error {
+	rules, err := parseValueRules(tagDef)
+	if err != nil {
+		return err
+	}
+	return s.checkVar(value, rules)
+}
+
+func (s *validation) checkVar(value interface{}, rules *ruleSet) error {
+	_, err := s.checkTags(s.ctx, "", fieldPath{}, "", rules, value)
+	return s.report(err)
+}
+
+// Chain is a tag-style validator chain parsed once to check many values, e.g.
+// the parts of a larger document.
+type Chain struct {
+	rules *ruleSet
+}
+
+// ParseChain parses a validator chain the way Var does.
+func ParseChain(tag string) (*Chain, error) {
+	rules, err := parseValueRules(tag)
+	if err != nil {
+		return nil, err
+	}
+	return &Chain{rules: rules}, nil
+}
+
+// Validate validates value against the chain. Unlike Var, it is a part of
+// the caller's validation: the Observer hears about the validator calls only,
+// the start and the end are reported by Observed.
+func (c *Chain) Validate(value interface{}, opts ...Option) error {
+	s := newValidation(context.Background(), opts...)
+	s.datumType = reflect.TypeOf(value)
+	return s.result(s.checkVar(value, c.rules))
+}
+
+// Observed reports validate to the Observer in opts as the validation of
+// datum, for the validations built out of Chain checks.
+func Observed(datum interface{}, validate func() error, opts ...Option) error {
+	s := newValidation(context.Background(), opts...)
+	s.start(datum)
+	return s.end(validate())
+}
@@ -0,0 +1,205 @@
+package validator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVar(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   interface{}
+		tag     string
+		wantErr string
+	}{
+		{name: "valid string", value: "foo", tag: "nonempty, maxlen(5)"},
+		{name: "too long string", value: "foobar", tag: "nonempty, maxlen(5)", wantErr: "Validation failed: length must be up to 5"},
+		{name: "empty string", value: "", tag: "nonempty, maxlen(5)", wantErr: "Validation failed: should not be empty"},
+		{name: "optional nil", value: nil, tag: "optional, nonempty"},
+		{name: "nil", value: nil, tag: "nonempty", wantErr: "Validation failed: should not be empty"},
+		{name: "int in range", value: 42, tag: "range(1, 100)"},
+		{name: "int out of range", value: 420, tag: "range(1, 100)", wantErr: "Validation failed: should be in the range [1, 100]"},
+		{name: "slice", value: []string{"foo", "bar"}, tag: "unique, maxitems(1)", wantErr: "Validation failed: should contain at most 1 items"},
+		{name: "unknown validator", value: 1, tag: "foo", wantErr: `Validator "foo" is unknown`},
+		{name: "normalizer", value: "  ", tag: "trim, nonempty", wantErr: `Normalizer "trim" can't be applied to a value passed by copy, normalize it before the validation`},
+		{name: "default", value: 0, tag: "default(5), gt(0)", wantErr: `Normalizer "default" can't be applied to a value passed by copy, normalize it before the validation`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Var(tt.value, tt.tag)
+			if tt.wantErr != "" {
+				assert.Error(t, err)
+				assert.Equal(t, tt.wantErr, err.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestChain(t *testing.T) {
+	chain, err := ParseChain("range(1, 100)")
+	assert.NoError(t, err)
+
+	o := &testObserver{}
+	err = Observed([]int{42, 420}, func() error {
+		for _, v := range []int{42, 420} {
+			if err := chain.Validate(v, WithObserver(o)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, WithObserver(o))
+	assert.EqualError(t, err, "Validation failed: should be in the range [1, 100]")
+	assert.Equal(t, []string{
+		"start []int",
+		"check int  range ok",
+		"check int  range fail",
+		"end []int fail",
+	}, o.events)
+
+	_, err = ParseChain("trim, nonempty")
+	assert.EqualError(t, err, `Normalizer "trim" can't be applied to a value passed by copy, normalize it before the validation`)
+}
+
+func TestVarWithValue(t *testing.T) {
+	var err error
+
+	err = VarWithValue("secret", "secret", "eqfield")
+	assert.NoError(t, err)
+
+	err = VarWithValue("secret", "Secret", "eqfield")
+	assert.Error(t, err)
+	assert.Equal(t, "Validation failed: should be equal to the compared value", err.Error())
+
+	err = VarWithValue(10, 5, "gtfield, ltefield")
+	assert.Error(t, err)
+	assert.Equal(t, "Validation failed: should be less or equal to the compared value", err.Error())
+
+	err = Var(10, "eqfield")
+	assert.Error(t, err)
+	assert.Equal(t, "Validation failed: no value to compare with", err.Error())
+}
+
+func TestCrossFieldValidators(t *testing.T) {
+	type TestStruct struct {
+		Password string `validate:"nonempty"`
+		Confirm  string `validate:"eqfield(Password)"`
+		Min      int
+		Max      int `validate:"gtefield(Min)"`
+	}
+
+	var err error
+
+	err = Validate(TestStruct{Password: "foo", Confirm: "foo", Min: 1, Max: 1})
+	assert.NoError(t, err)
+
+	err = Validate(TestStruct{Password: "foo", Confirm: "bar"})
+	assert.Error(t, err)
+	assert.Equal(t, `Validation failed for field "Confirm": should be equal to field Password`, err.Error())
+
+	err = Validate(TestStruct{Password: "foo", Confirm: "foo", Min: 2, Max: 1})
+	assert.Error(t, err)
+	assert.Equal(t, `Validation failed for field "Max": should be greater or equal to field Min`, err.Error())
+
+	type BrokenStruct struct {
+		Confirm string `validate:"eqfield(Missing)"`
+	}
+	err = Validate(BrokenStruct{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `has no field "Missing"`)
+}
+
+func TestCrossFieldValidators_FieldValues(t *testing.T) {
+	type TestStruct struct {
+		min  int
+		Max  int           `validate:"gtfield(min)"`
+		Idle time.Duration `validate:"optional, ltfield(TTL)"`
+		TTL  time.Duration
+	}
+
+	err := Validate(TestStruct{Max: 1})
+	assert.EqualError(t, err, `Validation failed for field "Max": field "min" is not exported`)
+
+	assert.NoError(t, Validate(TestStruct{Idle: time.Second, TTL: time.Minute}, WithoutFields("Max")))
+	err = Validate(TestStruct{Idle: time.Hour, TTL: time.Minute}, WithoutFields("Max"))
+	assert.EqualError(t, err, `Validation failed for field "Idle": should be less than field TTL`)
+}
+
+func TestValidateCollections(t *testing.T) {
+	type Item struct {
+		Title string `validate:"nonempty"`
+	}
+	type TestStruct struct {
+		Items []*Item
+		Index map[string]Item
+	}
+
+	tests := []struct {
+		name     string
+		input    interface{}
+		wantErr  string
+		wantPath string
+	}{
+		{
+			name:  "valid slice",
+			input: []Item{{Title: "foo"}, {Title: "bar"}},
+		},
+		{
+			name:     "invalid slice element",
+			input:    []Item{{Title: "foo"}, {}},
+			wantErr:  `Validation failed for field "Title": should not be empty`,
+			wantPath: "[1].Title",
+		},
+		{
+			name:     "invalid pointer slice element",
+			input:    &[]*Item{nil, {}},
+			wantErr:  `Validation failed for field "Title": should not be empty`,
+			wantPath: "[1].Title",
+		},
+		{
+			name:     "invalid map element",
+			input:    map[string]Item{"foo": {Title: "foo"}, "bar": {}},
+			wantErr:  `Validation failed for field "Title": should not be empty`,
+			wantPath: "[bar].Title",
+		},
+		{
+			name:     "nested slice",
+			input:    TestStruct{Items: []*Item{{Title: "foo"}, {}}},
+			wantErr:  `Validation failed for field "Title": should not be empty`,
+			wantPath: "Items[1].Title",
+		},
+		{
+			name:     "nested map",
+			input:    TestStruct{Index: map[string]Item{"foo": {}}},
+			wantErr:  `Validation failed for field "Title": should not be empty`,
+			wantPath: "Index[foo].Title",
+		},
+		{
+			name:    "not a struct collection",
+			input:   []int{1, 2},
+			wantErr: "Validate accepts a struct or a collection of structs, []int{1, 2} []int given",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.input)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Equal(t, tt.wantErr, err.Error())
+			if tt.wantPath != "" {
+				fe, ok := err.(*FieldError)
+				if assert.True(t, ok) {
+					assert.Equal(t, tt.wantPath, fe.Path)
+				}
+			}
+		})
+	}
+}
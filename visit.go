@@ -0,0 +1,40 @@
+package validator
+
+import "reflect"
+
+type visitKey struct {
+	ptr uintptr
+	// len tells apart the slices sharing the backing array
+	len int
+	typ reflect.Type
+}
+
+func newVisitKey(v reflect.Value) visitKey {
+	key := visitKey{ptr: v.Pointer(), typ: v.Type()}
+	if v.Kind() == reflect.Slice {
+		key.len = v.Len()
+	}
+	return key
+}
+
+// enter marks the struct the pointer v points to, or the elements of the
+// slice or map v, as being validated and reports whether they already are,
+// i.e. v refers back to one of its ancestors. This keeps cyclic graphs from
+// being walked forever; a shared object is still validated on every path
+// leading to it, which the field selection relies on. leave must be called
+// once v is validated unless enter reported a cycle.
+func (s *validation) enter(v reflect.Value) bool {
+	key := newVisitKey(v)
+	if s.visited[key] {
+		return true
+	}
+	if s.visited == nil {
+		s.visited = make(map[visitKey]bool)
+	}
+	s.visited[key] = true
+	return false
+}
+
+func (s *validation) leave(v reflect.Value) {
+	delete(s.visited, newVisitKey(v))
+}
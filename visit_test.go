@@ -0,0 +1,146 @@
+package validator
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testListNode struct {
+	Val  int `validate:"gt(0)"`
+	Next *testListNode
+}
+
+type testTreeNode struct {
+	Name     string `validate:"nonempty"`
+	Parent   *testTreeNode
+	Children []*testTreeNode
+}
+
+func TestValidateCyclicList(t *testing.T) {
+	a := &testListNode{Val: 1}
+	b := &testListNode{Val: 2}
+	c := &testListNode{Val: 3}
+	a.Next, b.Next, c.Next = b, c, a
+
+	var err error
+
+	err = Validate(a)
+	assert.NoError(t, err)
+
+	c.Val = 0
+	err = Validate(a)
+	assert.Error(t, err)
+	fe, ok := err.(*FieldError)
+	if assert.True(t, ok) {
+		assert.Equal(t, "Next.Next.Val", fe.Path)
+	}
+
+	self := &testListNode{Val: 1}
+	self.Next = self
+	err = Validate(*self)
+	assert.NoError(t, err)
+}
+
+func TestValidateCyclicTree(t *testing.T) {
+	root := &testTreeNode{Name: "root"}
+	left := &testTreeNode{Name: "left", Parent: root}
+	right := &testTreeNode{Name: "right", Parent: root}
+	root.Children = []*testTreeNode{left, right}
+	leaf := &testTreeNode{Name: "leaf", Parent: right}
+	right.Children = []*testTreeNode{leaf, left}
+
+	var err error
+
+	err = Validate(root)
+	assert.NoError(t, err)
+
+	err = Validate(leaf)
+	assert.NoError(t, err)
+
+	leaf.Name = ""
+	err = Validate(left)
+	assert.Error(t, err)
+	fe, ok := err.(*FieldError)
+	if assert.True(t, ok) {
+		assert.Equal(t, "Parent.Children[1].Children[0].Name", fe.Path)
+	}
+}
+
+func TestValidateMaxDepth(t *testing.T) {
+	list := &testListNode{Val: 1, Next: &testListNode{Val: 2, Next: &testListNode{Val: 3}}}
+
+	var err error
+
+	err = Validate(list, WithMaxDepth(3))
+	assert.NoError(t, err)
+
+	err = Validate(list, WithMaxDepth(2))
+	assert.Error(t, err)
+	var depthErr *DepthError
+	if assert.True(t, errors.As(err, &depthErr)) {
+		assert.Equal(t, 2, depthErr.MaxDepth)
+		assert.Equal(t, "Next.Next", depthErr.Path)
+	}
+	assert.Equal(t, `Maximum validation depth of 2 exceeded at "Next.Next"`, err.Error())
+}
+
+type testGraphNode struct {
+	Name string `validate:"nonempty"`
+	Kids []testGraphNode
+	Refs map[string]testGraphNode
+}
+
+func TestValidateCyclicCollections(t *testing.T) {
+	s := make([]testGraphNode, 1)
+	s[0] = testGraphNode{Name: "a"}
+	s[0].Kids = s
+	assert.NoError(t, Validate(s[0]))
+	assert.NoError(t, Validate(s))
+
+	s[0].Name = ""
+	assert.EqualError(t, Validate(&testGraphNode{Name: "root", Kids: s}), `Validation failed for field "Name": should not be empty`)
+
+	m := map[string]testGraphNode{}
+	m["a"] = testGraphNode{Name: "a", Refs: m}
+	assert.NoError(t, Validate(testGraphNode{Name: "root", Refs: m}))
+
+	m["b"] = testGraphNode{Refs: m}
+	err := Validate(testGraphNode{Name: "root", Refs: m})
+	fe, ok := err.(*FieldError)
+	if assert.True(t, ok) {
+		assert.Equal(t, "Refs[b].Name", fe.Path)
+	}
+}
+
+type testSharedLeaf struct {
+	Name  string `validate:"nonempty"`
+	Other string `validate:"nonempty"`
+}
+
+type testSharedRoot struct {
+	X *testSharedLeaf
+	Y *testSharedLeaf
+}
+
+func TestValidateSharedPointer(t *testing.T) {
+	leaf := &testSharedLeaf{}
+	paths := func(err error) []string {
+		var errs ValidationErrors
+		if !errors.As(err, &errs) {
+			return nil
+		}
+		res := []string{}
+		for _, fe := range errs {
+			res = append(res, fe.Path)
+		}
+		return res
+	}
+
+	err := Validate(testSharedRoot{X: leaf, Y: leaf}, WithFields("X.Other", "Y.Name"), WithAllErrors())
+	assert.Equal(t, []string{"X.Other", "Y.Name"}, paths(err))
+
+	err = Validate(testSharedRoot{X: leaf, Y: leaf}, WithAllErrors())
+	assert.Equal(t, []string{"X.Name", "X.Other", "Y.Name", "Y.Other"}, paths(err))
+}